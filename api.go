@@ -1,14 +1,13 @@
 package github
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-	"os"
+	neturl "net/url"
 	"strings"
+	"sync"
 )
 
 type CommentList []Comment
@@ -34,7 +33,7 @@ func (l CommentList) Less(i, j int) bool {
 }
 
 type Comment struct {
-	Errors   []Error
+	Id       int64  `json:"id"`
 	CommitId string `json:"commit_id"`
 	Path     string
 	Position int
@@ -42,6 +41,16 @@ type Comment struct {
 	Body     string `json:"body"`
 	Created  string `json:"created_at"`
 	User     User
+
+	// The remaining fields are only set on pull request review comments.
+	DiffHunk         string `json:"diff_hunk"`
+	OriginalCommitId string `json:"original_commit_id"`
+	OriginalLine     int    `json:"original_line"`
+	Side             string `json:"side"`
+	StartLine        int    `json:"start_line"`
+	StartSide        string `json:"start_side"`
+	InReplyTo        int64  `json:"in_reply_to_id"`
+	ReviewId         int64  `json:"pull_request_review_id"`
 }
 
 type BodyOnlyComment struct {
@@ -49,28 +58,111 @@ type BodyOnlyComment struct {
 }
 
 type User struct {
-	Login string
+	Login string `json:"login"`
 }
 
+// Error is a single entry in the "errors" array of a GitHub error response.
 type Error struct {
-	Message string
+	Resource string
+	Field    string
+	Code     string
+	Message  string
+}
+
+// UnmarshalJSON accepts either an error object or, as some endpoints
+// return, a bare string, which is stored in Message.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var msg string
+	if json.Unmarshal(data, &msg) == nil {
+		*e = Error{Message: msg}
+		return nil
+	}
+	// errorFields has Error's fields but not this method.
+	type errorFields Error
+	return json.Unmarshal(data, (*errorFields)(e))
+}
+
+func (e Error) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s.%s: %s", e.Resource, e.Field, e.Code)
+}
+
+// APIError is returned by ApiClient methods when GitHub responds with a
+// non-2xx status code.
+type APIError struct {
+	StatusCode       int    `json:"-"`
+	Message          string `json:"message"`
+	Errors           []Error
+	DocumentationURL string `json:"documentation_url"`
 }
 
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%d %s", e.StatusCode, e.Message)
+	for _, err := range e.Errors {
+		msg += "; " + err.Error()
+	}
+	return msg
+}
+
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	// Keep whatever decoded even if the body is malformed further on.
+	json.Unmarshal(body, apiErr)
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(statusCode)
+	}
+	return apiErr
+}
+
+// PullRequest is a pull request. Its JSON field names match GitHub's, so
+// it can be passed on to other tools as is.
 type PullRequest struct {
-	Errors   []Error
-	Head     Commit
-	Base     Commit
-	Number   int
+	Head     Commit `json:"head"`
+	Base     Commit `json:"base"`
+	Number   int    `json:"number"`
+	NodeId   string `json:"node_id"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	State    string `json:"state"`
 	IssueUrl string `json:"issue_url"`
+	HtmlUrl  string `json:"html_url"`
+	Draft    bool   `json:"draft"`
+	Merged   bool   `json:"merged"`
+	// Mergeable is nil while GitHub is still computing it.
+	Mergeable      *bool  `json:"mergeable"`
+	MergeableState string `json:"mergeable_state"`
+
+	RequestedReviewers []User `json:"requested_reviewers"`
+	RequestedTeams     []Team `json:"requested_teams"`
+
+	Labels    []Label    `json:"labels"`
+	Assignees []User     `json:"assignees"`
+	Milestone *Milestone `json:"milestone"`
+}
+
+// PullRequestUpdate holds the fields to change in UpdatePullRequest. Nil
+// fields are left unchanged.
+type PullRequestUpdate struct {
+	Title *string `json:"title,omitempty"`
+	Body  *string `json:"body,omitempty"`
+	Base  *string `json:"base,omitempty"`
+	// State is "open" or "closed".
+	State               *string `json:"state,omitempty"`
+	MaintainerCanModify *bool   `json:"maintainer_can_modify,omitempty"`
 }
 
 type Commit struct {
-	SHA  string
-	Repo Repo
+	SHA  string `json:"sha"`
+	Ref  string `json:"ref"`
+	Repo Repo   `json:"repo"`
 }
 
 type Repo struct {
-	Name string
+	Name          string `json:"name"`
+	Owner         User   `json:"owner"`
+	DefaultBranch string `json:"default_branch"`
 }
 
 type createPullRequestRequest struct {
@@ -78,12 +170,14 @@ type createPullRequestRequest struct {
 	Body  string `json:"body"`
 	Base  string `json:"base"`
 	Head  string `json:"head"`
+	Draft bool   `json:"draft,omitempty"`
 }
 
 type createPullRequestFromIssueRequest struct {
 	Issue int    `json:"issue"`
 	Base  string `json:"base"`
 	Head  string `json:"head"`
+	Draft bool   `json:"draft,omitempty"`
 }
 
 /*
@@ -93,129 +187,185 @@ var (
 )
 */
 
+// DefaultBaseURL and DefaultUploadURL are the API endpoints for github.com.
+const (
+	DefaultBaseURL   = "https://api.github.com/"
+	DefaultUploadURL = "https://uploads.github.com/"
+)
+
 type ApiClient struct {
 	OAuthToken, User string
 	Debug            bool
+	// Cache, if set, stores GET responses and revalidates them with
+	// conditional requests, which do not count against the rate limit.
+	Cache *Cache
+	// TokenSource, if set, supplies the token for each request in place
+	// of OAuthToken.
+	TokenSource TokenSource
+	// CredentialsSource describes where OAuthToken was found.
+	CredentialsSource string
+	// CredentialsSkipped explains why the credential sources tried before
+	// CredentialsSource had no credentials. Both are printed before the
+	// first request if Debug is set.
+	CredentialsSkipped []string
+	// BaseURL and UploadURL are the API endpoints, with a trailing slash.
+	// If empty, DefaultBaseURL and DefaultUploadURL are used.
+	BaseURL, UploadURL string
+	// HTTPClient sends requests. Set it to configure timeouts, proxies,
+	// TLS roots or a custom Transport. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// APIVersion is sent in the X-GitHub-Api-Version header. If empty,
+	// DefaultAPIVersion is used.
+	APIVersion string
+	// Retry controls how failed requests are retried. If nil,
+	// DefaultRetryPolicy is used.
+	Retry *RetryPolicy
+
+	mu        sync.Mutex
+	rates     map[string]Rate
+	debugOnce sync.Once
 }
 
-func ApiClientFromHubCredentials() (*ApiClient, error) {
-	fname := os.Getenv("HOME") + "/.config/hub"
-	data, err := ioutil.ReadFile(fname)
-	if err != nil {
-		return nil, errors.New("Could not read " + fname)
+// HostURLs returns the API and upload endpoints for a GitHub host. Hosts
+// other than github.com are assumed to be GitHub Enterprise Server.
+func HostURLs(host string) (baseURL, uploadURL string) {
+	if host == "" || host == "github.com" {
+		return DefaultBaseURL, DefaultUploadURL
 	}
-	var user, token string
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		if i := strings.Index(line, "user: "); i != -1 {
-			user = line[i+len("user: "):]
-		} else if i := strings.Index(line, "oauth_token: "); i != -1 {
-			token = line[i+len("oauth_token: "):]
-		}
-	}
-	if user == "" || token == "" {
-		return nil, errors.New("Could not read user and token")
-	}
-	return &ApiClient{OAuthToken: token, User: user}, nil
+	return "https://" + host + "/api/v3/", "https://" + host + "/api/uploads/"
 }
 
-func (c *ApiClient) load(url string, data []byte) ([]byte, error) {
-	method := "GET"
-	if data != nil {
-		method = "POST"
-	}
-	if c.Debug {
-		fmt.Print("DEBUG: REQUEST: ", url, "\n", string(data), "\n")
+func (c *ApiClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
 	}
-	req, err := http.NewRequest(method, url, bytes.NewReader(data))
-	if err != nil {
-		return nil, err
-	}
-	req.ContentLength = int64(len(data))
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.OAuthToken))
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	return http.DefaultClient
+}
+
+// url returns the API URL for the path built from format and args.
+func (c *ApiClient) url(format string, args ...interface{}) string {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
 	}
-	if c.Debug {
-		fmt.Print("DEBUG: RESPONSE: ", string(body), "\n")
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
 	}
-	return body, nil
+	return base + fmt.Sprintf(format, args...)
 }
 
-func (c *ApiClient) GetOpenPullRequests(user, project string) []PullRequest {
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/pulls?status=open",
+// ListOpenPullRequests calls fn with each page of open pull requests. If
+// head is not empty, only pull requests from that branch, given as
+// "owner:branch", are listed.
+func (c *ApiClient) ListOpenPullRequests(ctx context.Context, user, project, head string, opts *ListOptions, fn func([]PullRequest) error) error {
+	url := c.url(
+		"repos/%s/%s/pulls?state=open",
 		user,
 		project)
-	body, _ := c.load(url, nil)
+	if head != "" {
+		url += "&head=" + neturl.QueryEscape(head)
+	}
+	return c.eachPage(ctx, url, opts, func(page []json.RawMessage) error {
+		var pulls []PullRequest
+		if err := decodePage(url, page, &pulls); err != nil {
+			return err
+		}
+		return fn(pulls)
+	})
+}
+
+// GetOpenPullRequests returns the open pull requests from every page,
+// filtered by head as in ListOpenPullRequests.
+func (c *ApiClient) GetOpenPullRequests(ctx context.Context, user, project, head string, opts *ListOptions) ([]PullRequest, error) {
 	var pulls []PullRequest
-	json.Unmarshal(body, &pulls)
-	return pulls
+	err := c.ListOpenPullRequests(ctx, user, project, head, opts, func(page []PullRequest) error {
+		pulls = append(pulls, page...)
+		return nil
+	})
+	return pulls, err
 }
 
-func (c *ApiClient) GetPullRequest(user, project string, id int) PullRequest {
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/pulls/%d",
+func (c *ApiClient) GetPullRequest(ctx context.Context, user, project string, id int) (PullRequest, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d",
 		user,
 		project,
 		id)
-	body, _ := c.load(url, nil)
 	var pull PullRequest
-	json.Unmarshal(body, &pull)
-	return pull
+	err := c.get(ctx, url, &pull)
+	return pull, err
 }
 
-func (c *ApiClient) GetPullRequestComments(user, project string, pull int) CommentList {
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/issues/%d/comments",
+// ListPullRequestComments calls fn with each page of conversation comments
+// on a pull request.
+func (c *ApiClient) ListPullRequestComments(ctx context.Context, user, project string, pull int, opts *ListOptions, fn func(CommentList) error) error {
+	url := c.url(
+		"repos/%s/%s/issues/%d/comments",
 		user,
 		project,
 		pull)
-	body, _ := c.load(url, nil)
+	return c.eachComment(ctx, url, opts, fn)
+}
+
+// GetPullRequestComments returns the conversation comments on a pull request
+// from every page.
+func (c *ApiClient) GetPullRequestComments(ctx context.Context, user, project string, pull int, opts *ListOptions) (CommentList, error) {
 	var comments CommentList
-	json.Unmarshal(body, &comments)
-	return comments
+	err := c.ListPullRequestComments(ctx, user, project, pull, opts, func(page CommentList) error {
+		comments = append(comments, page...)
+		return nil
+	})
+	return comments, err
 }
 
-func (c *ApiClient) GetCommitComments(user, project, sha string) CommentList {
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/commits/%s/comments",
+// ListCommitComments calls fn with each page of comments on a commit.
+func (c *ApiClient) ListCommitComments(ctx context.Context, user, project, sha string, opts *ListOptions, fn func(CommentList) error) error {
+	url := c.url(
+		"repos/%s/%s/commits/%s/comments",
 		user,
 		project,
 		sha)
-	body, _ := c.load(url, nil)
-	var comments []Comment
-	json.Unmarshal(body, &comments)
-	return comments
+	return c.eachComment(ctx, url, opts, fn)
 }
 
-func (c *ApiClient) CommentOnPullRequest(user, project string, pull int, body string) Comment {
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/issues/%d/comments",
+// GetCommitComments returns the comments on a commit from every page.
+func (c *ApiClient) GetCommitComments(ctx context.Context, user, project, sha string, opts *ListOptions) (CommentList, error) {
+	var comments CommentList
+	err := c.ListCommitComments(ctx, user, project, sha, opts, func(page CommentList) error {
+		comments = append(comments, page...)
+		return nil
+	})
+	return comments, err
+}
+
+func (c *ApiClient) eachComment(ctx context.Context, url string, opts *ListOptions, fn func(CommentList) error) error {
+	return c.eachPage(ctx, url, opts, func(page []json.RawMessage) error {
+		var comments CommentList
+		if err := decodePage(url, page, &comments); err != nil {
+			return err
+		}
+		return fn(comments)
+	})
+}
+
+func (c *ApiClient) CommentOnPullRequest(ctx context.Context, user, project string, pull int, body string) (Comment, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d/comments",
 		user,
 		project,
 		pull)
 	req := &BodyOnlyComment{Body: body}
-	var err error
-	var data []byte
-	if data, err = json.Marshal(req); err != nil {
-		panic(err)
-	}
-	result, _ := c.load(url, data)
 	var comment Comment
-	json.Unmarshal(result, &comment)
-	return comment
+	err := c.post(ctx, url, req, &comment)
+	return comment, err
 }
 
-func (c *ApiClient) CreatePullRequest(user, project, title, body, head, base string) PullRequest {
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/pulls",
+// CreatePullRequest opens a pull request from head into base. If draft is
+// set it is opened as a draft, which cannot be merged until it is marked
+// ready for review.
+func (c *ApiClient) CreatePullRequest(ctx context.Context, user, project, title, body, head, base string, draft bool) (PullRequest, error) {
+	url := c.url(
+		"repos/%s/%s/pulls",
 		user,
 		project)
 	req := &createPullRequestRequest{
@@ -223,35 +373,85 @@ func (c *ApiClient) CreatePullRequest(user, project, title, body, head, base str
 		Body:  body,
 		Base:  base,
 		Head:  head,
+		Draft: draft,
 	}
-	var err error
-	var data []byte
-	if data, err = json.Marshal(req); err != nil {
-		panic(err)
-	}
-	result, _ := c.load(url, data)
 	var pull PullRequest
-	json.Unmarshal(result, &pull)
-	return pull
+	err := c.post(ctx, url, req, &pull)
+	return pull, err
 }
 
-func (c *ApiClient) CreatePullRequestFromIssue(user, project string, issue int, head, base string) PullRequest {
-	url := fmt.Sprintf(
-		"https://api.github.com/repos/%s/%s/pulls",
+// CreatePullRequestFromIssue converts an issue into a pull request from head
+// into base, optionally as a draft.
+func (c *ApiClient) CreatePullRequestFromIssue(ctx context.Context, user, project string, issue int, head, base string, draft bool) (PullRequest, error) {
+	url := c.url(
+		"repos/%s/%s/pulls",
 		user,
 		project)
 	req := &createPullRequestFromIssueRequest{
 		Issue: issue,
 		Base:  base,
 		Head:  head,
+		Draft: draft,
 	}
-	var err error
-	var data []byte
-	if data, err = json.Marshal(req); err != nil {
-		panic(err)
-	}
-	result, _ := c.load(url, data)
 	var pull PullRequest
-	json.Unmarshal(result, &pull)
-	return pull
+	err := c.post(ctx, url, req, &pull)
+	return pull, err
+}
+
+// UpdatePullRequest changes the fields of a pull request that are set in
+// update.
+func (c *ApiClient) UpdatePullRequest(ctx context.Context, user, project string, id int, update *PullRequestUpdate) (PullRequest, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d",
+		user,
+		project,
+		id)
+	var pull PullRequest
+	err := c.do(ctx, "PATCH", url, update, &pull)
+	return pull, err
+}
+
+// ClosePullRequest closes a pull request without merging it.
+func (c *ApiClient) ClosePullRequest(ctx context.Context, user, project string, id int) (PullRequest, error) {
+	state := "closed"
+	return c.UpdatePullRequest(ctx, user, project, id, &PullRequestUpdate{State: &state})
+}
+
+// ReopenPullRequest reopens a closed pull request.
+func (c *ApiClient) ReopenPullRequest(ctx context.Context, user, project string, id int) (PullRequest, error) {
+	state := "open"
+	return c.UpdatePullRequest(ctx, user, project, id, &PullRequestUpdate{State: &state})
+}
+
+// EditComment replaces the body of a conversation comment.
+func (c *ApiClient) EditComment(ctx context.Context, user, project string, id int64, body string) (Comment, error) {
+	url := c.url(
+		"repos/%s/%s/issues/comments/%d",
+		user,
+		project,
+		id)
+	var comment Comment
+	err := c.do(ctx, "PATCH", url, &BodyOnlyComment{Body: body}, &comment)
+	return comment, err
+}
+
+// DeleteComment deletes a conversation comment.
+func (c *ApiClient) DeleteComment(ctx context.Context, user, project string, id int64) error {
+	url := c.url(
+		"repos/%s/%s/issues/comments/%d",
+		user,
+		project,
+		id)
+	return c.do(ctx, "DELETE", url, nil, nil)
+}
+
+// RemoveLabel removes a label from an issue or pull request.
+func (c *ApiClient) RemoveLabel(ctx context.Context, user, project string, issue int, label string) error {
+	url := c.url(
+		"repos/%s/%s/issues/%d/labels/%s",
+		user,
+		project,
+		issue,
+		neturl.PathEscape(label))
+	return c.do(ctx, "DELETE", url, nil, nil)
 }
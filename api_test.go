@@ -0,0 +1,27 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   string
+	}{
+		{422, `{"message":"Validation Failed","errors":[{"resource":"Issue","field":"title","code":"missing_field"}]}`,
+			"422 Validation Failed; Issue.title: missing_field"},
+		{422, `{"message":"Unprocessable Entity","errors":["Head sha can't be blank"]}`,
+			"422 Unprocessable Entity; Head sha can't be blank"},
+		{422, `{"message":"Reviews may only be requested from collaborators.","errors":["x",{"message":"y"}]}`,
+			"422 Reviews may only be requested from collaborators.; x; y"},
+		{502, `<html>Bad Gateway</html>`, "502 Bad Gateway"},
+		{404, `{}`, "404 Not Found"},
+	}
+	for _, test := range tests {
+		if got := newAPIError(test.status, []byte(test.body)).Error(); got != test.want {
+			t.Errorf("newAPIError(%d, %s) = %q, want %q", test.status, test.body, got, test.want)
+		}
+	}
+}
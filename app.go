@@ -0,0 +1,187 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A TokenSource supplies the token used to authenticate a request.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// AppTokenSource authenticates as an installation of a GitHub App. It signs
+// a JWT with the app's private key, exchanges it for an installation access
+// token, and caches that token until shortly before it expires.
+type AppTokenSource struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     *rsa.PrivateKey
+	// BaseURL is the API endpoint used to create installation tokens. If
+	// empty, DefaultBaseURL is used.
+	BaseURL string
+	// HTTPClient sends token requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// tokenRefreshMargin is how long before expiry a cached installation token
+// is replaced.
+const tokenRefreshMargin = 5 * time.Minute
+
+// NewAppTokenSource returns a token source for an app installation, given
+// the PEM-encoded private key downloaded from the app's settings.
+func NewAppTokenSource(appID, installationID int64, privateKeyPEM []byte) (*AppTokenSource, error) {
+	key, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &AppTokenSource{AppID: appID, InstallationID: installationID, PrivateKey: key}, nil
+}
+
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM-encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %s", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// JWT returns an RS256-signed token that authenticates as the app itself.
+func (s *AppTokenSource) JWT() (string, error) {
+	now := time.Now()
+	header := `{"alg":"RS256","typ":"JWT"}`
+	// Backdate the issue time to allow for clock drift; GitHub rejects
+	// tokens that expire more than ten minutes out.
+	claims := fmt.Sprintf(`{"iat":%d,"exp":%d,"iss":"%d"}`,
+		now.Add(-time.Minute).Unix(), now.Add(9*time.Minute).Unix(), s.AppID)
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString([]byte(claims))
+	hash := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
+
+// Token returns a cached installation token, creating a new one if there is
+// none or it is about to expire.
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Until(s.expires) > tokenRefreshMargin {
+		return s.token, nil
+	}
+	token, expires, err := s.createInstallationToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not create installation token: %w", err)
+	}
+	s.token, s.expires = token, expires
+	return token, nil
+}
+
+func (s *AppTokenSource) createInstallationToken(ctx context.Context) (string, time.Time, error) {
+	jwt, err := s.JWT()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	base := s.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", base, s.InstallationID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", time.Time{}, newAPIError(resp.StatusCode, body)
+	}
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := decode(url, body, &result); err != nil {
+		return "", time.Time{}, err
+	}
+	if result.Token == "" {
+		return "", time.Time{}, errors.New("no token in response from " + url)
+	}
+	return result.Token, result.ExpiresAt, nil
+}
+
+// AppTokenSourceFromEnv returns a token source configured by the
+// GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID and GITHUB_APP_PRIVATE_KEY_FILE
+// environment variables, or nil if GITHUB_APP_ID is not set.
+func AppTokenSourceFromEnv(host string) (*AppTokenSource, error) {
+	if os.Getenv("GITHUB_APP_ID") == "" {
+		return nil, nil
+	}
+	appID, err := strconv.ParseInt(os.Getenv("GITHUB_APP_ID"), 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid $GITHUB_APP_ID")
+	}
+	installationID, err := strconv.ParseInt(os.Getenv("GITHUB_APP_INSTALLATION_ID"), 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid $GITHUB_APP_INSTALLATION_ID")
+	}
+	fname := os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE")
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, errors.New("Could not read $GITHUB_APP_PRIVATE_KEY_FILE " + fname)
+	}
+	s, err := NewAppTokenSource(appID, installationID, data)
+	if err != nil {
+		return nil, err
+	}
+	s.BaseURL, _ = HostURLs(host)
+	return s, nil
+}
@@ -0,0 +1,130 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verifyJWT checks that jwt is signed by key and returns its claims.
+func verifyJWT(t *testing.T, key *rsa.PublicKey, jwt string) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT %q does not have 3 parts", jwt)
+	}
+	enc := base64.RawURLEncoding
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("JWT signature: %v", err)
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig); err != nil {
+		t.Fatalf("JWT signature does not verify: %v", err)
+	}
+	var header, claims map[string]interface{}
+	for i, v := range []*map[string]interface{}{&header, &claims} {
+		data, err := enc.DecodeString(parts[i])
+		if err != nil {
+			t.Fatalf("JWT part %d: %v", i, err)
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatalf("JWT part %d %s: %v", i, data, err)
+		}
+	}
+	if header["alg"] != "RS256" {
+		t.Errorf("JWT alg = %v, want RS256", header["alg"])
+	}
+	return claims
+}
+
+// appServer creates installation tokens for app 42, installation 7, which
+// expire after lifetime. It records the Authorization header of each
+// request.
+type appServer struct {
+	key      *rsa.PrivateKey
+	lifetime time.Duration
+	auth     []string
+}
+
+func newAppServer(t *testing.T, lifetime time.Duration) (*AppTokenSource, *appServer) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &appServer{key: key, lifetime: lifetime}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/app/installations/7/access_tokens" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		s.auth = append(s.auth, r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `{"token":"tok-%d","expires_at":%q}`, len(s.auth), time.Now().Add(s.lifetime).Format(time.RFC3339))
+	}))
+	t.Cleanup(srv.Close)
+	return &AppTokenSource{AppID: 42, InstallationID: 7, PrivateKey: key, BaseURL: srv.URL}, s
+}
+
+func TestAppTokenSourceJWT(t *testing.T) {
+	src, s := newAppServer(t, time.Hour)
+	if _, err := src.Token(context.Background()); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if len(s.auth) != 1 || !strings.HasPrefix(s.auth[0], "Bearer ") {
+		t.Fatalf("Authorization = %q, want one Bearer token", s.auth)
+	}
+	claims := verifyJWT(t, &s.key.PublicKey, strings.TrimPrefix(s.auth[0], "Bearer "))
+	if claims["iss"] != "42" {
+		t.Errorf("iss = %v, want \"42\"", claims["iss"])
+	}
+	now := float64(time.Now().Unix())
+	iat, _ := claims["iat"].(float64)
+	exp, _ := claims["exp"].(float64)
+	if iat > now || iat < now-120 {
+		t.Errorf("iat = %v, want shortly before %v", iat, now)
+	}
+	if exp <= now || exp > now+600 {
+		t.Errorf("exp = %v, want within ten minutes of %v", exp, now)
+	}
+}
+
+func TestAppTokenSourceCachesToken(t *testing.T) {
+	src, s := newAppServer(t, time.Hour)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		token, err := src.Token(ctx)
+		if err != nil {
+			t.Fatalf("Token: %v", err)
+		}
+		if token != "tok-1" {
+			t.Errorf("Token = %q, want tok-1", token)
+		}
+	}
+	if len(s.auth) != 1 {
+		t.Errorf("created %d tokens, want 1", len(s.auth))
+	}
+}
+
+func TestAppTokenSourceRefreshesExpiringToken(t *testing.T) {
+	src, s := newAppServer(t, tokenRefreshMargin/2)
+	ctx := context.Background()
+	if _, err := src.Token(ctx); err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	token, err := src.Token(ctx)
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if token != "tok-2" || len(s.auth) != 2 {
+		t.Errorf("Token = %q after creating %d tokens, want tok-2 after 2", token, len(s.auth))
+	}
+}
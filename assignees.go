@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"errors"
+)
+
+type assigneesRequest struct {
+	Assignees []string `json:"assignees"`
+}
+
+// assigneesResponse picks the assignees out of an issue or pull request.
+type assigneesResponse struct {
+	Assignees []User
+}
+
+// GetAssignees returns the users assigned to an issue or pull request.
+func (c *ApiClient) GetAssignees(ctx context.Context, user, project string, issue int) ([]User, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d",
+		user,
+		project,
+		issue)
+	var result assigneesResponse
+	err := c.get(ctx, url, &result)
+	return result.Assignees, err
+}
+
+// AddAssignees assigns users to an issue or pull request, and returns all
+// of its assignees. Users who cannot be assigned are silently ignored by
+// GitHub; use IsAssignable to check first.
+func (c *ApiClient) AddAssignees(ctx context.Context, user, project string, issue int, logins []string) ([]User, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d/assignees",
+		user,
+		project,
+		issue)
+	var result assigneesResponse
+	err := c.post(ctx, url, &assigneesRequest{Assignees: logins}, &result)
+	return result.Assignees, err
+}
+
+// RemoveAssignees unassigns users from an issue or pull request, and
+// returns the remaining assignees.
+func (c *ApiClient) RemoveAssignees(ctx context.Context, user, project string, issue int, logins []string) ([]User, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d/assignees",
+		user,
+		project,
+		issue)
+	var result assigneesResponse
+	err := c.do(ctx, "DELETE", url, &assigneesRequest{Assignees: logins}, &result)
+	return result.Assignees, err
+}
+
+// SetAssignees replaces the assignees of an issue or pull request. An empty
+// list removes them all.
+func (c *ApiClient) SetAssignees(ctx context.Context, user, project string, issue int, logins []string) ([]User, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d",
+		user,
+		project,
+		issue)
+	if logins == nil {
+		logins = []string{}
+	}
+	var result assigneesResponse
+	err := c.do(ctx, "PATCH", url, &assigneesRequest{Assignees: logins}, &result)
+	return result.Assignees, err
+}
+
+// IsAssignable reports whether login can be assigned to issues in a
+// repository.
+func (c *ApiClient) IsAssignable(ctx context.Context, user, project, login string) (bool, error) {
+	url := c.url(
+		"repos/%s/%s/assignees/%s",
+		user,
+		project,
+		login)
+	err := c.get(ctx, url, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,152 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache stores GET responses on disk, keyed by URL, along with the ETag and
+// Last-Modified validators needed to revalidate them.
+type Cache struct {
+	Dir string
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// CacheStats counts how requests made through a Cache were served.
+type CacheStats struct {
+	// Hits are requests answered with 304 Not Modified and served from
+	// the cache.
+	Hits int
+	// Misses are requests that downloaded a full response.
+	Misses int
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("%d hits, %d misses", s.Hits, s.Misses)
+}
+
+type cacheEntry struct {
+	ETag         string
+	LastModified string
+	Header       http.Header
+	Body         []byte
+}
+
+// DefaultCacheDir returns $XDG_CACHE_HOME/github-go, or ~/.cache/github-go
+// if XDG_CACHE_HOME is not set.
+func DefaultCacheDir() string {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		dir = filepath.Join(os.Getenv("HOME"), ".cache")
+	}
+	return filepath.Join(dir, "github-go")
+}
+
+// NewCache returns a cache that stores responses in dir, or in
+// DefaultCacheDir if dir is empty.
+func NewCache(dir string) *Cache {
+	if dir == "" {
+		dir = DefaultCacheDir()
+	}
+	return &Cache{Dir: dir}
+}
+
+// Stats returns the number of hits and misses so far.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+func (c *Cache) hit() {
+	c.mu.Lock()
+	c.stats.Hits++
+	c.mu.Unlock()
+}
+
+func (c *Cache) miss() {
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+}
+
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+// get returns the stored entry for url, or nil if there is none.
+func (c *Cache) get(url string) *cacheEntry {
+	data, err := ioutil.ReadFile(c.path(url))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if json.Unmarshal(data, &entry) != nil {
+		return nil
+	}
+	return &entry
+}
+
+// put stores a response for url if it carries a validator.
+func (c *Cache) put(url string, header http.Header, body []byte) error {
+	entry := &cacheEntry{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Header:       http.Header{},
+		Body:         body,
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+	// Keep only the headers callers use, so pagination still works when a
+	// response is served from the cache.
+	if link := header.Get("Link"); link != "" {
+		entry.Header.Set("Link", link)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	// Write to a temporary file and rename so that concurrent readers never
+	// see a partial entry.
+	tmp, err := ioutil.TempFile(c.Dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(url))
+}
+
+// setConditions makes req conditional on the entry's validators.
+func (e *cacheEntry) setConditions(req *http.Request) {
+	if e == nil {
+		return
+	}
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
@@ -0,0 +1,214 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// App is the GitHub App that owns a check run or suite.
+type App struct {
+	Id   int64 `json:"id"`
+	Slug string
+	Name string
+}
+
+// CheckRun is a single check reported by a GitHub App.
+type CheckRun struct {
+	Id      int64 `json:"id"`
+	Name    string
+	HeadSHA string `json:"head_sha"`
+	// Status is "queued", "in_progress" or "completed".
+	Status string
+	// Conclusion is set once Status is "completed": "success", "failure",
+	// "neutral", "cancelled", "skipped", "timed_out" or "action_required".
+	Conclusion  string
+	DetailsUrl  string `json:"details_url"`
+	HtmlUrl     string `json:"html_url"`
+	StartedAt   string `json:"started_at"`
+	CompletedAt string `json:"completed_at"`
+	Output      CheckRunOutput
+	CheckSuite  struct {
+		Id int64 `json:"id"`
+	} `json:"check_suite"`
+	App App
+}
+
+// CheckRunOutput is the report shown for a check run.
+type CheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+	Text    string `json:"text,omitempty"`
+	// Annotations are only sent when creating or updating a check run.
+	Annotations []CheckRunAnnotation `json:"annotations,omitempty"`
+}
+
+// Annotation levels for CheckRunAnnotation.
+const (
+	AnnotationNotice  = "notice"
+	AnnotationWarning = "warning"
+	AnnotationFailure = "failure"
+)
+
+// CheckRunAnnotation attaches a message to lines of a file in a check run.
+// Path is relative to the top of the repository.
+type CheckRunAnnotation struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	// AnnotationLevel is AnnotationNotice, AnnotationWarning or
+	// AnnotationFailure.
+	AnnotationLevel string `json:"annotation_level"`
+	Message         string `json:"message"`
+	Title           string `json:"title,omitempty"`
+	RawDetails      string `json:"raw_details,omitempty"`
+}
+
+// maxAnnotations is the most annotations GitHub accepts in one request.
+const maxAnnotations = 50
+
+// CheckRunRequest creates or updates a check run. Empty fields are left
+// unchanged by UpdateCheckRun.
+type CheckRunRequest struct {
+	Name    string `json:"name,omitempty"`
+	HeadSHA string `json:"head_sha,omitempty"`
+	// Status is "queued", "in_progress" or "completed". Setting
+	// Conclusion implies "completed".
+	Status      string          `json:"status,omitempty"`
+	Conclusion  string          `json:"conclusion,omitempty"`
+	DetailsUrl  string          `json:"details_url,omitempty"`
+	ExternalId  string          `json:"external_id,omitempty"`
+	StartedAt   string          `json:"started_at,omitempty"`
+	CompletedAt string          `json:"completed_at,omitempty"`
+	Output      *CheckRunOutput `json:"output,omitempty"`
+}
+
+// CheckSuite groups the check runs created by one app for a commit.
+type CheckSuite struct {
+	Id         int64  `json:"id"`
+	HeadBranch string `json:"head_branch"`
+	HeadSHA    string `json:"head_sha"`
+	// Status and Conclusion take the same values as for CheckRun.
+	Status     string
+	Conclusion string
+	App        App
+}
+
+// ListCheckRuns calls fn with each page of check runs on ref, such as a
+// pull request's Head.SHA.
+func (c *ApiClient) ListCheckRuns(ctx context.Context, user, project, ref string, opts *ListOptions, fn func([]CheckRun) error) error {
+	url := c.url(
+		"repos/%s/%s/commits/%s/check-runs",
+		user,
+		project,
+		ref)
+	return c.eachWrappedPage(ctx, url, "check_runs", opts, func(page []json.RawMessage) error {
+		var runs []CheckRun
+		if err := decodePage(url, page, &runs); err != nil {
+			return err
+		}
+		return fn(runs)
+	})
+}
+
+// GetCheckRuns returns the check runs on ref from every page.
+func (c *ApiClient) GetCheckRuns(ctx context.Context, user, project, ref string, opts *ListOptions) ([]CheckRun, error) {
+	var runs []CheckRun
+	err := c.ListCheckRuns(ctx, user, project, ref, opts, func(page []CheckRun) error {
+		runs = append(runs, page...)
+		return nil
+	})
+	return runs, err
+}
+
+// ListCheckSuites calls fn with each page of check suites on ref.
+func (c *ApiClient) ListCheckSuites(ctx context.Context, user, project, ref string, opts *ListOptions, fn func([]CheckSuite) error) error {
+	url := c.url(
+		"repos/%s/%s/commits/%s/check-suites",
+		user,
+		project,
+		ref)
+	return c.eachWrappedPage(ctx, url, "check_suites", opts, func(page []json.RawMessage) error {
+		var suites []CheckSuite
+		if err := decodePage(url, page, &suites); err != nil {
+			return err
+		}
+		return fn(suites)
+	})
+}
+
+// GetCheckSuites returns the check suites on ref from every page.
+func (c *ApiClient) GetCheckSuites(ctx context.Context, user, project, ref string, opts *ListOptions) ([]CheckSuite, error) {
+	var suites []CheckSuite
+	err := c.ListCheckSuites(ctx, user, project, ref, opts, func(page []CheckSuite) error {
+		suites = append(suites, page...)
+		return nil
+	})
+	return suites, err
+}
+
+// CreateCheckRun starts a check run on req.HeadSHA. Check runs can only be
+// created when authenticated as a GitHub App. Annotations beyond the 50
+// that GitHub accepts per request are sent in follow-up updates.
+func (c *ApiClient) CreateCheckRun(ctx context.Context, user, project string, req *CheckRunRequest) (CheckRun, error) {
+	url := c.url(
+		"repos/%s/%s/check-runs",
+		user,
+		project)
+	first, rest := splitAnnotations(req)
+	var run CheckRun
+	if err := c.post(ctx, url, first, &run); err != nil {
+		return run, err
+	}
+	return c.sendAnnotations(ctx, user, project, run, req.Output, rest)
+}
+
+// UpdateCheckRun changes a check run, typically to complete it with a
+// conclusion and output. Annotations are added to any already on the run.
+func (c *ApiClient) UpdateCheckRun(ctx context.Context, user, project string, id int64, req *CheckRunRequest) (CheckRun, error) {
+	url := c.url(
+		"repos/%s/%s/check-runs/%d",
+		user,
+		project,
+		id)
+	first, rest := splitAnnotations(req)
+	var run CheckRun
+	if err := c.do(ctx, "PATCH", url, first, &run); err != nil {
+		return run, err
+	}
+	return c.sendAnnotations(ctx, user, project, run, req.Output, rest)
+}
+
+// splitAnnotations returns a copy of req with at most maxAnnotations
+// annotations, and the annotations left over.
+func splitAnnotations(req *CheckRunRequest) (*CheckRunRequest, []CheckRunAnnotation) {
+	if req.Output == nil || len(req.Output.Annotations) <= maxAnnotations {
+		return req, nil
+	}
+	first := *req
+	output := *req.Output
+	output.Annotations = output.Annotations[:maxAnnotations]
+	first.Output = &output
+	return &first, req.Output.Annotations[maxAnnotations:]
+}
+
+// sendAnnotations adds annotations to run in batches. GitHub requires the
+// title and summary to be repeated with each batch.
+func (c *ApiClient) sendAnnotations(ctx context.Context, user, project string, run CheckRun, output *CheckRunOutput, annotations []CheckRunAnnotation) (CheckRun, error) {
+	for len(annotations) > 0 {
+		n := len(annotations)
+		if n > maxAnnotations {
+			n = maxAnnotations
+		}
+		batch := &CheckRunRequest{Output: &CheckRunOutput{
+			Title:       output.Title,
+			Summary:     output.Summary,
+			Annotations: annotations[:n],
+		}}
+		annotations = annotations[n:]
+		var err error
+		if run, err = c.UpdateCheckRun(ctx, user, project, run.Id, batch); err != nil {
+			return run, err
+		}
+	}
+	return run, nil
+}
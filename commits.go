@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// RepositoryCommit is a commit as returned by the commit, compare and pull
+// request commit endpoints. Author and Committer are the GitHub users
+// matching the git author and committer, if any.
+type RepositoryCommit struct {
+	SHA       string
+	NodeId    string `json:"node_id"`
+	Commit    GitCommit
+	Author    *User
+	Committer *User
+	Parents   []struct {
+		SHA string
+	}
+	HtmlUrl string `json:"html_url"`
+	// Files is only set by GetCommit.
+	Files []PullRequestFile
+}
+
+// GitCommit holds the git metadata of a commit.
+type GitCommit struct {
+	Author    CommitAuthor
+	Committer CommitAuthor
+	Message   string
+	Tree      struct {
+		SHA string
+	}
+	Verification Verification
+}
+
+// CommitAuthor identifies the git author or committer of a commit. Date
+// may be left empty when passing it to CreateOrUpdateFile or DeleteFile.
+type CommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date,omitempty"`
+}
+
+// Verification describes whether a commit's signature was verified.
+type Verification struct {
+	Verified bool
+	// Reason is "valid" for verified commits, and otherwise explains why
+	// not, such as "unsigned" or "unknown_key".
+	Reason    string
+	Signature string
+	Payload   string
+}
+
+// Comparison is the result of comparing two refs.
+type Comparison struct {
+	// Status is "ahead", "behind", "identical" or "diverged", describing
+	// head relative to base.
+	Status          string
+	AheadBy         int              `json:"ahead_by"`
+	BehindBy        int              `json:"behind_by"`
+	TotalCommits    int              `json:"total_commits"`
+	BaseCommit      RepositoryCommit `json:"base_commit"`
+	MergeBaseCommit RepositoryCommit `json:"merge_base_commit"`
+	// Commits lists the commits from the merge base to head, oldest
+	// first. GitHub returns at most 250.
+	Commits []RepositoryCommit
+	Files   []PullRequestFile
+	HtmlUrl string `json:"html_url"`
+}
+
+// Compare compares two commits, branches or tags. head may be given as
+// "owner:branch" to compare across forks.
+func (c *ApiClient) Compare(ctx context.Context, user, project, base, head string) (Comparison, error) {
+	url := c.url(
+		"repos/%s/%s/compare/%s...%s",
+		user,
+		project,
+		base,
+		head)
+	var comparison Comparison
+	err := c.get(ctx, url, &comparison)
+	return comparison, err
+}
+
+// GetCommit returns a commit, including the files it changed.
+func (c *ApiClient) GetCommit(ctx context.Context, user, project, ref string) (RepositoryCommit, error) {
+	url := c.url(
+		"repos/%s/%s/commits/%s",
+		user,
+		project,
+		ref)
+	var commit RepositoryCommit
+	err := c.get(ctx, url, &commit)
+	return commit, err
+}
+
+// ListPullRequestCommits calls fn with each page of commits in a pull
+// request, oldest first. GitHub lists at most 250 commits.
+func (c *ApiClient) ListPullRequestCommits(ctx context.Context, user, project string, pull int, opts *ListOptions, fn func([]RepositoryCommit) error) error {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/commits",
+		user,
+		project,
+		pull)
+	return c.eachPage(ctx, url, opts, func(page []json.RawMessage) error {
+		var commits []RepositoryCommit
+		if err := decodePage(url, page, &commits); err != nil {
+			return err
+		}
+		return fn(commits)
+	})
+}
+
+// GetPullRequestCommits returns the commits in a pull request from every
+// page.
+func (c *ApiClient) GetPullRequestCommits(ctx context.Context, user, project string, pull int, opts *ListOptions) ([]RepositoryCommit, error) {
+	var commits []RepositoryCommit
+	err := c.ListPullRequestCommits(ctx, user, project, pull, opts, func(page []RepositoryCommit) error {
+		commits = append(commits, page...)
+		return nil
+	})
+	return commits, err
+}
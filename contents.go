@@ -0,0 +1,170 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// RepositoryContent is a file, directory, symlink or submodule in a
+// repository. Content is only set for files fetched with GetContents, and
+// not for files larger than 1 MB; use GetRawContents for those.
+type RepositoryContent struct {
+	// Type is "file", "dir", "symlink" or "submodule".
+	Type string
+	Name string
+	Path string
+	SHA  string
+	Size int
+	// Encoding is "base64", or "none" if the file was too large.
+	Encoding string
+	Content  string
+	// Target is the destination of a symlink.
+	Target      string
+	HtmlUrl     string `json:"html_url"`
+	DownloadUrl string `json:"download_url"`
+}
+
+// Decode returns the decoded content of a file.
+func (rc *RepositoryContent) Decode() ([]byte, error) {
+	if rc.Type != "file" {
+		return nil, fmt.Errorf("%s is a %s, not a file", rc.Path, rc.Type)
+	}
+	if rc.Encoding != "base64" {
+		return nil, fmt.Errorf("%s has no content in the response (encoding %q); use GetRawContents", rc.Path, rc.Encoding)
+	}
+	// GitHub wraps the encoded content at 60 characters.
+	data, err := base64.StdEncoding.DecodeString(strings.Replace(rc.Content, "\n", "", -1))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s: %s", rc.Path, err)
+	}
+	return data, nil
+}
+
+// FileRequest creates, updates or deletes a file. SHA is the blob SHA of
+// the file being replaced or deleted; GitHub rejects the request with 409
+// Conflict if the file has changed since, and with 422 if SHA is empty but
+// the file already exists. Branch defaults to the repository's default
+// branch.
+type FileRequest struct {
+	Message string `json:"message"`
+	// Content is the new content of the file. It is not used by
+	// DeleteFile.
+	Content   []byte        `json:"content"`
+	SHA       string        `json:"sha,omitempty"`
+	Branch    string        `json:"branch,omitempty"`
+	Author    *CommitAuthor `json:"author,omitempty"`
+	Committer *CommitAuthor `json:"committer,omitempty"`
+}
+
+// deleteFileRequest is a FileRequest without the content.
+type deleteFileRequest struct {
+	Message   string        `json:"message"`
+	SHA       string        `json:"sha"`
+	Branch    string        `json:"branch,omitempty"`
+	Author    *CommitAuthor `json:"author,omitempty"`
+	Committer *CommitAuthor `json:"committer,omitempty"`
+}
+
+// ContentsResponse is the result of changing a file. Content is nil when
+// the file was deleted.
+type ContentsResponse struct {
+	Content *RepositoryContent
+	Commit  ContentsCommit
+}
+
+// ContentsCommit is the commit that changed a file.
+type ContentsCommit struct {
+	SHA     string
+	HtmlUrl string `json:"html_url"`
+	GitCommit
+}
+
+// contentsURL returns the URL of path in a repository, escaping each of its
+// components.
+func (c *ApiClient) contentsURL(user, project, path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		parts[i] = neturl.PathEscape(part)
+	}
+	return c.url(
+		"repos/%s/%s/contents/%s",
+		user,
+		project,
+		strings.Join(parts, "/"))
+}
+
+func refQuery(ref string) neturl.Values {
+	if ref == "" {
+		return nil
+	}
+	return neturl.Values{"ref": {ref}}
+}
+
+// GetContents returns the file at path, or the listing of the directory at
+// path, at ref. If ref is empty, the repository's default branch is used.
+// Exactly one of file and dir is set when err is nil.
+func (c *ApiClient) GetContents(ctx context.Context, user, project, path, ref string) (file *RepositoryContent, dir []RepositoryContent, err error) {
+	url := c.contentsURL(user, project, path)
+	body, _, err := c.DoRaw(ctx, &Request{Method: "GET", URL: url, Query: refQuery(ref)})
+	if err != nil {
+		return nil, nil, err
+	}
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		err = decode(url, body, &dir)
+		return nil, dir, err
+	}
+	file = &RepositoryContent{}
+	if err := decode(url, body, file); err != nil {
+		return nil, nil, err
+	}
+	return file, nil, nil
+}
+
+// GetRawContents returns the content of the file at path at ref, without
+// the 1 MB limit of GetContents.
+func (c *ApiClient) GetRawContents(ctx context.Context, user, project, path, ref string) ([]byte, error) {
+	header := http.Header{"Accept": {RawMediaType}}
+	body, _, err := c.DoRaw(ctx, &Request{
+		Method: "GET",
+		URL:    c.contentsURL(user, project, path),
+		Query:  refQuery(ref),
+		Header: header,
+	})
+	return body, err
+}
+
+// CreateOrUpdateFile commits req.Content to path. Set req.SHA to the blob
+// SHA returned by GetContents to update an existing file.
+func (c *ApiClient) CreateOrUpdateFile(ctx context.Context, user, project, path string, req *FileRequest) (ContentsResponse, error) {
+	// A nil Content would be sent as null rather than an empty file.
+	if req.Content == nil {
+		empty := *req
+		empty.Content = []byte{}
+		req = &empty
+	}
+	var result ContentsResponse
+	err := c.do(ctx, "PUT", c.contentsURL(user, project, path), req, &result)
+	return result, err
+}
+
+// DeleteFile commits the deletion of the file at path. req.SHA must be the
+// file's current blob SHA.
+func (c *ApiClient) DeleteFile(ctx context.Context, user, project, path string, req *FileRequest) (ContentsResponse, error) {
+	if req.SHA == "" {
+		return ContentsResponse{}, fmt.Errorf("the SHA of %s is required to delete it", path)
+	}
+	body := &deleteFileRequest{
+		Message:   req.Message,
+		SHA:       req.SHA,
+		Branch:    req.Branch,
+		Author:    req.Author,
+		Committer: req.Committer,
+	}
+	var result ContentsResponse
+	err := c.do(ctx, "DELETE", c.contentsURL(user, project, path), body, &result)
+	return result, err
+}
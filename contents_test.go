@@ -0,0 +1,151 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// contentsServer records the last request and responds with body.
+type contentsServer struct {
+	method, path, rawPath, query string
+	body                         map[string]interface{}
+	response                     string
+}
+
+func newContentsServer(t *testing.T, response string) (*ApiClient, *contentsServer) {
+	s := &contentsServer{response: response}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.method, s.path, s.rawPath, s.query = r.Method, r.URL.Path, r.URL.EscapedPath(), r.URL.RawQuery
+		s.body = nil
+		if data, _ := ioutil.ReadAll(r.Body); len(data) > 0 {
+			if err := json.Unmarshal(data, &s.body); err != nil {
+				t.Errorf("request body %s: %v", data, err)
+			}
+		}
+		w.Write([]byte(s.response))
+	}))
+	t.Cleanup(srv.Close)
+	return &ApiClient{BaseURL: srv.URL + "/"}, s
+}
+
+func TestGetContentsFile(t *testing.T) {
+	c, s := newContentsServer(t, `{"type":"file","path":"VERSION","sha":"abc","encoding":"base64","content":"MS4y\nLjMK\n"}`)
+	file, dir, err := c.GetContents(context.Background(), "o", "r", "VERSION", "release/1.x")
+	if err != nil {
+		t.Fatalf("GetContents: %v", err)
+	}
+	if dir != nil || file == nil {
+		t.Fatalf("GetContents returned file %v and dir %v, want only a file", file, dir)
+	}
+	if s.path != "/repos/o/r/contents/VERSION" {
+		t.Errorf("path = %s", s.path)
+	}
+	if s.query != "ref=release%2F1.x" {
+		t.Errorf("query = %s, want ref=release%%2F1.x", s.query)
+	}
+	data, err := file.Decode()
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if string(data) != "1.2.3\n" {
+		t.Errorf("Decode = %q, want %q", data, "1.2.3\n")
+	}
+}
+
+func TestGetContentsDirectory(t *testing.T) {
+	c, s := newContentsServer(t, `[{"type":"file","name":"a.go","path":"dir/a.go"},{"type":"dir","name":"sub","path":"dir/sub"}]`)
+	file, dir, err := c.GetContents(context.Background(), "o", "r", "dir", "")
+	if err != nil {
+		t.Fatalf("GetContents: %v", err)
+	}
+	if file != nil || len(dir) != 2 {
+		t.Fatalf("GetContents returned file %v and dir %v, want a listing of 2", file, dir)
+	}
+	if dir[1].Type != "dir" || dir[1].Path != "dir/sub" {
+		t.Errorf("dir[1] = %+v", dir[1])
+	}
+	if s.query != "" {
+		t.Errorf("query = %s, want none", s.query)
+	}
+	if _, err := dir[1].Decode(); err == nil {
+		t.Error("Decode of a directory: got nil, want an error")
+	}
+}
+
+func TestGetContentsEscapesPath(t *testing.T) {
+	c, s := newContentsServer(t, `{"type":"file","path":"docs/release notes.md"}`)
+	if _, _, err := c.GetContents(context.Background(), "o", "r", "docs/release notes.md", ""); err != nil {
+		t.Fatalf("GetContents: %v", err)
+	}
+	if want := "/repos/o/r/contents/docs/release%20notes.md"; s.rawPath != want {
+		t.Errorf("path = %s, want %s", s.rawPath, want)
+	}
+}
+
+func TestCreateOrUpdateFile(t *testing.T) {
+	c, s := newContentsServer(t, `{"content":{"path":"VERSION","sha":"def"},"commit":{"sha":"c1"}}`)
+	result, err := c.CreateOrUpdateFile(context.Background(), "o", "r", "VERSION", &FileRequest{
+		Message: "Bump version",
+		Content: []byte("1.2.4\n"),
+		SHA:     "abc",
+		Branch:  "main",
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateFile: %v", err)
+	}
+	if s.method != "PUT" || s.path != "/repos/o/r/contents/VERSION" {
+		t.Errorf("request = %s %s", s.method, s.path)
+	}
+	want := map[string]interface{}{
+		"message": "Bump version",
+		"content": "MS4yLjQK",
+		"sha":     "abc",
+		"branch":  "main",
+	}
+	for k, v := range want {
+		if s.body[k] != v {
+			t.Errorf("body[%q] = %v, want %v", k, s.body[k], v)
+		}
+	}
+	if result.Commit.SHA != "c1" || result.Content.SHA != "def" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestCreateEmptyFile(t *testing.T) {
+	c, s := newContentsServer(t, `{}`)
+	if _, err := c.CreateOrUpdateFile(context.Background(), "o", "r", ".gitkeep", &FileRequest{Message: "m"}); err != nil {
+		t.Fatalf("CreateOrUpdateFile: %v", err)
+	}
+	if content, ok := s.body["content"]; !ok || content != "" {
+		t.Errorf("content = %v (present %v), want an empty string", content, ok)
+	}
+}
+
+func TestDeleteFile(t *testing.T) {
+	c, s := newContentsServer(t, `{"content":null,"commit":{"sha":"c2"}}`)
+	ctx := context.Background()
+	if _, err := c.DeleteFile(ctx, "o", "r", "VERSION", &FileRequest{Message: "m"}); err == nil {
+		t.Error("DeleteFile without a SHA: got nil, want an error")
+	}
+	if s.method != "" {
+		t.Errorf("DeleteFile without a SHA sent a %s request", s.method)
+	}
+	result, err := c.DeleteFile(ctx, "o", "r", "VERSION", &FileRequest{Message: "m", SHA: "abc", Content: []byte("x")})
+	if err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if s.method != "DELETE" || s.body["sha"] != "abc" {
+		t.Errorf("request = %s %v", s.method, s.body)
+	}
+	if _, ok := s.body["content"]; ok {
+		t.Errorf("DeleteFile sent content")
+	}
+	if result.Content != nil || result.Commit.SHA != "c2" {
+		t.Errorf("result = %+v", result)
+	}
+}
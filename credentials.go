@@ -0,0 +1,275 @@
+package github
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Credentials authenticate requests to a GitHub host.
+type Credentials struct {
+	User, Token string
+	// Source describes where the credentials were found.
+	Source string
+	// Skipped explains why each source tried before Source had no
+	// credentials, as "name: reason".
+	Skipped []string
+}
+
+// A CredentialSource looks up credentials for a host. Lookup returns an
+// error explaining why when it has no credentials for the host.
+type CredentialSource struct {
+	Name   string
+	Lookup func(host string) (*Credentials, error)
+}
+
+// DefaultCredentialSources are tried in order by ApiClientForHost.
+var DefaultCredentialSources = []CredentialSource{
+	EnvCredentials,
+	GhCredentials,
+	HubCredentials,
+	NetrcCredentials,
+	GitCredentials,
+}
+
+// FindCredentials returns the credentials from the first source that has
+// them for host, recording why the sources before it failed in Skipped. If
+// none do, the error lists why each source failed.
+func FindCredentials(host string, sources []CredentialSource) (*Credentials, error) {
+	var skipped []string
+	for _, source := range sources {
+		creds, err := source.Lookup(host)
+		if err == nil {
+			creds.Skipped = skipped
+			return creds, nil
+		}
+		skipped = append(skipped, fmt.Sprintf("%s: %s", source.Name, err))
+	}
+	return nil, errors.New("Could not find credentials for " + host + ":\n  " + strings.Join(skipped, "\n  "))
+}
+
+// ApiClientForHost returns a client for host. It authenticates as a GitHub
+// App installation if AppTokenSourceFromEnv is configured, and otherwise
+// uses the first credentials found in DefaultCredentialSources.
+func ApiClientForHost(host string) (*ApiClient, error) {
+	app, err := AppTokenSourceFromEnv(host)
+	if err != nil {
+		return nil, err
+	}
+	if app != nil {
+		c := newApiClient(host, &Credentials{Source: fmt.Sprintf("GitHub App %d", app.AppID)})
+		c.TokenSource = app
+		return c, nil
+	}
+	creds, err := FindCredentials(host, DefaultCredentialSources)
+	if err != nil {
+		return nil, err
+	}
+	return newApiClient(host, creds), nil
+}
+
+// ApiClientFromHubCredentials returns a client for host using the
+// credentials stored for that host in hub's configuration file.
+func ApiClientFromHubCredentials(host string) (*ApiClient, error) {
+	creds, err := HubCredentials.Lookup(host)
+	if err != nil {
+		return nil, err
+	}
+	return newApiClient(host, creds), nil
+}
+
+func newApiClient(host string, creds *Credentials) *ApiClient {
+	c := &ApiClient{
+		OAuthToken:         creds.Token,
+		User:               creds.User,
+		CredentialsSource:  creds.Source,
+		CredentialsSkipped: creds.Skipped,
+	}
+	c.BaseURL, c.UploadURL = HostURLs(host)
+	return c
+}
+
+// debugCredentials prints where the client's credentials came from and why
+// the sources tried before it were skipped.
+func (c *ApiClient) debugCredentials() {
+	if c.CredentialsSource == "" {
+		return
+	}
+	fmt.Printf("DEBUG: using credentials from %s\n", c.CredentialsSource)
+	for _, skipped := range c.CredentialsSkipped {
+		fmt.Printf("DEBUG:   skipped %s\n", skipped)
+	}
+}
+
+// EnvCredentials reads a token from GITHUB_TOKEN or GH_TOKEN for github.com,
+// and from GITHUB_ENTERPRISE_TOKEN or GH_ENTERPRISE_TOKEN for other hosts.
+var EnvCredentials = CredentialSource{
+	Name: "environment",
+	Lookup: func(host string) (*Credentials, error) {
+		vars := []string{"GITHUB_TOKEN", "GH_TOKEN"}
+		if host != "github.com" {
+			vars = []string{"GITHUB_ENTERPRISE_TOKEN", "GH_ENTERPRISE_TOKEN"}
+		}
+		for _, v := range vars {
+			if token := os.Getenv(v); token != "" {
+				return &Credentials{Token: token, Source: "$" + v}, nil
+			}
+		}
+		return nil, fmt.Errorf("neither $%s nor $%s is set", vars[0], vars[1])
+	},
+}
+
+// GhCredentials reads the hosts.yml file written by the gh CLI.
+var GhCredentials = CredentialSource{
+	Name: "gh",
+	Lookup: func(host string) (*Credentials, error) {
+		dir := os.Getenv("GH_CONFIG_DIR")
+		if dir == "" {
+			dir = filepath.Join(configDir(), "gh")
+		}
+		fname := filepath.Join(dir, "hosts.yml")
+		entry, err := readYAMLHost(fname, host)
+		if err != nil {
+			return nil, err
+		}
+		m, _ := entry.(map[string]interface{})
+		token, _ := m["oauth_token"].(string)
+		user, _ := m["user"].(string)
+		if token == "" {
+			return nil, fmt.Errorf("no oauth_token for %s in %s (gh may keep it in the system keyring)", host, fname)
+		}
+		return &Credentials{User: user, Token: token, Source: fname}, nil
+	},
+}
+
+// HubCredentials reads the configuration file written by hub.
+var HubCredentials = CredentialSource{
+	Name: "hub",
+	Lookup: func(host string) (*Credentials, error) {
+		fname := os.Getenv("HUB_CONFIG")
+		if fname == "" {
+			fname = filepath.Join(configDir(), "hub")
+		}
+		entry, err := readYAMLHost(fname, host)
+		if err != nil {
+			return nil, err
+		}
+		// hub stores a list of accounts per host; use the first.
+		if list, ok := entry.([]interface{}); ok && len(list) > 0 {
+			entry = list[0]
+		}
+		m, _ := entry.(map[string]interface{})
+		token, _ := m["oauth_token"].(string)
+		user, _ := m["user"].(string)
+		if user == "" || token == "" {
+			return nil, fmt.Errorf("could not read user and oauth_token for %s in %s", host, fname)
+		}
+		return &Credentials{User: user, Token: token, Source: fname}, nil
+	},
+}
+
+// NetrcCredentials reads $NETRC or ~/.netrc, matching either the host or
+// its API host (e.g. api.github.com).
+var NetrcCredentials = CredentialSource{
+	Name: "netrc",
+	Lookup: func(host string) (*Credentials, error) {
+		fname := os.Getenv("NETRC")
+		if fname == "" {
+			fname = filepath.Join(os.Getenv("HOME"), ".netrc")
+		}
+		data, err := ioutil.ReadFile(fname)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s", fname)
+		}
+		machines := map[string]bool{host: true, "api." + host: true}
+		var login, password string
+		matched, done := false, false
+		fields := strings.Fields(string(data))
+		for i := 0; i < len(fields) && !done; i++ {
+			switch fields[i] {
+			case "machine", "default":
+				if matched && password != "" {
+					done = true
+					break
+				}
+				matched = fields[i] == "default" || (i+1 < len(fields) && machines[fields[i+1]])
+				login, password = "", ""
+				if fields[i] == "machine" {
+					i++
+				}
+			case "login", "password", "account":
+				if i+1 < len(fields) && matched {
+					if fields[i] == "login" {
+						login = fields[i+1]
+					} else if fields[i] == "password" {
+						password = fields[i+1]
+					}
+				}
+				i++
+			}
+		}
+		if !matched || password == "" {
+			return nil, fmt.Errorf("no machine %s with a password in %s", host, fname)
+		}
+		return &Credentials{User: login, Token: password, Source: fname}, nil
+	},
+}
+
+// GitCredentials asks the configured git credential helpers, without
+// prompting, for https credentials for host.
+var GitCredentials = CredentialSource{
+	Name: "git credential",
+	Lookup: func(host string) (*Credentials, error) {
+		cmd := exec.Command("git", "credential", "fill")
+		cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("'git credential fill' failed: %s", strings.TrimSpace(stderr.String()))
+		}
+		var user, password string
+		for _, line := range strings.Split(string(output), "\n") {
+			if strings.HasPrefix(line, "username=") {
+				user = strings.TrimPrefix(line, "username=")
+			} else if strings.HasPrefix(line, "password=") {
+				password = strings.TrimPrefix(line, "password=")
+			}
+		}
+		if password == "" {
+			return nil, errors.New("no password returned by 'git credential fill'")
+		}
+		return &Credentials{User: user, Token: password, Source: "git credential helper"}, nil
+	},
+}
+
+func configDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config")
+}
+
+// readYAMLHost returns the top-level entry for host in a YAML file.
+func readYAMLHost(fname, host string) (interface{}, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s", fname)
+	}
+	doc, err := parseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", fname, err)
+	}
+	hosts, _ := doc.(map[string]interface{})
+	entry, ok := hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("no entry for %s in %s", host, fname)
+	}
+	return entry, nil
+}
@@ -0,0 +1,145 @@
+package github
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func fakeSource(name string, creds *Credentials) CredentialSource {
+	return CredentialSource{Name: name, Lookup: func(host string) (*Credentials, error) {
+		if creds == nil {
+			return nil, errors.New("nothing for " + host)
+		}
+		return creds, nil
+	}}
+}
+
+func TestFindCredentialsRecordsSkipped(t *testing.T) {
+	sources := []CredentialSource{
+		fakeSource("first", nil),
+		fakeSource("second", nil),
+		fakeSource("third", &Credentials{Token: "t", Source: "third"}),
+	}
+	creds, err := FindCredentials("github.com", sources)
+	if err != nil {
+		t.Fatalf("FindCredentials: %v", err)
+	}
+	want := []string{"first: nothing for github.com", "second: nothing for github.com"}
+	if strings.Join(creds.Skipped, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Skipped = %q, want %q", creds.Skipped, want)
+	}
+	if c := newApiClient("github.com", creds); len(c.CredentialsSkipped) != 2 {
+		t.Errorf("CredentialsSkipped = %q, want 2 entries", c.CredentialsSkipped)
+	}
+}
+
+func TestFindCredentialsNone(t *testing.T) {
+	_, err := FindCredentials("example.com", []CredentialSource{fakeSource("first", nil), fakeSource("second", nil)})
+	want := "Could not find credentials for example.com:\n  first: nothing for example.com\n  second: nothing for example.com"
+	if err == nil || err.Error() != want {
+		t.Errorf("FindCredentials error = %v, want %q", err, want)
+	}
+}
+
+func TestNetrcCredentials(t *testing.T) {
+	tests := []struct {
+		name, netrc, host string
+		want              *Credentials // nil if no entry should match
+	}{
+		{
+			name:  "machine",
+			netrc: "machine example.com login x password y\nmachine github.com\n  login alice\n  password tok\n",
+			host:  "github.com",
+			want:  &Credentials{User: "alice", Token: "tok"},
+		},
+		{
+			name:  "api host",
+			netrc: "machine api.github.com login alice password tok\n",
+			host:  "github.com",
+			want:  &Credentials{User: "alice", Token: "tok"},
+		},
+		{
+			name:  "first match wins",
+			netrc: "machine github.com login alice password one\nmachine api.github.com login bob password two\n",
+			host:  "github.com",
+			want:  &Credentials{User: "alice", Token: "one"},
+		},
+		{
+			name:  "account ignored",
+			netrc: "machine github.com account acct login alice password tok",
+			host:  "github.com",
+			want:  &Credentials{User: "alice", Token: "tok"},
+		},
+		{
+			name:  "default",
+			netrc: "machine example.com login x password y\ndefault login anon password tok\n",
+			host:  "github.com",
+			want:  &Credentials{User: "anon", Token: "tok"},
+		},
+		{
+			name:  "machine before default",
+			netrc: "machine github.com login alice password tok\ndefault login anon password other\n",
+			host:  "github.com",
+			want:  &Credentials{User: "alice", Token: "tok"},
+		},
+		{
+			name:  "no password",
+			netrc: "machine github.com login alice\n",
+			host:  "github.com",
+		},
+		{
+			name:  "other host",
+			netrc: "machine gitlab.com login alice password tok\n",
+			host:  "github.com",
+		},
+		{
+			name:  "host is not a login",
+			netrc: "machine example.com login github.com password tok\n",
+			host:  "github.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fname := filepath.Join(t.TempDir(), "netrc")
+			if err := ioutil.WriteFile(fname, []byte(tt.netrc), 0600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("NETRC", fname)
+			creds, err := NetrcCredentials.Lookup(tt.host)
+			if tt.want == nil {
+				if err == nil {
+					t.Errorf("Lookup = %+v, want an error", creds)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup: %v", err)
+			}
+			if creds.User != tt.want.User || creds.Token != tt.want.Token || creds.Source != fname {
+				t.Errorf("Lookup = %+v, want %+v from %s", creds, tt.want, fname)
+			}
+		})
+	}
+}
+
+func TestConfigFileCredentials(t *testing.T) {
+	dir := t.TempDir()
+	hub := filepath.Join(dir, "hub")
+	ioutil.WriteFile(hub, []byte("github.com:\n- user: alice\n  oauth_token: first\n- user: bob\n  oauth_token: second\n"), 0600)
+	t.Setenv("HUB_CONFIG", hub)
+	if creds, err := HubCredentials.Lookup("github.com"); err != nil || creds.User != "alice" || creds.Token != "first" {
+		t.Errorf("hub Lookup = %+v, %v; want alice's first token", creds, err)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "hosts.yml"), []byte("ghe.example.com:\n    oauth_token: other\ngithub.com:\n    users:\n        alice:\n    oauth_token: 'gho_#1'\n    user: alice\n"), 0600)
+	t.Setenv("GH_CONFIG_DIR", dir)
+	if creds, err := GhCredentials.Lookup("github.com"); err != nil || creds.User != "alice" || creds.Token != "gho_#1" {
+		t.Errorf("gh Lookup = %+v, %v; want alice with gho_#1", creds, err)
+	}
+	if _, err := GhCredentials.Lookup("example.com"); err == nil {
+		t.Error("gh Lookup for a missing host: got nil, want an error")
+	}
+}
@@ -0,0 +1,126 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Editor returns the editor configured by core.editor, $VISUAL or $EDITOR,
+// falling back to vi.
+func Editor() (string, error) {
+	data, err := exec.Command("git", "config", "core.editor").Output()
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	// Editor not set in git config; try environment variables
+	editor := os.Getenv("VISUAL")
+	if editor != "" {
+		return editor, nil
+	}
+	editor = os.Getenv("EDITOR")
+	if editor != "" {
+		return editor, nil
+	}
+	return "vi", nil
+}
+
+// EditText opens the editor on a private temporary file containing
+// initial, and returns what the user saved. If commentChar is not empty,
+// lines starting with it are dropped.
+func EditText(initial, commentChar string) (string, error) {
+	editor, err := Editor()
+	if err != nil {
+		return "", err
+	}
+	// Use a private file rather than .git/COMMIT_EDITMSG, which belongs to
+	// git.
+	f, err := ioutil.TempFile("", "EDITMSG-*.md")
+	if err != nil {
+		return "", err
+	}
+	fname := f.Name()
+	defer os.Remove(fname)
+	_, err = f.WriteString(initial)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	editorPath, err := exec.LookPath(editor)
+	if err != nil {
+		return "", err
+	}
+	pa := &os.ProcAttr{Env: os.Environ(), Files: []*os.File{os.Stdin, os.Stdout, os.Stderr}}
+	p, err := os.StartProcess(editorPath, []string{editorPath, fname}, pa)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Could not start editor '%s': %s", editorPath, err))
+	}
+	ps, err := p.Wait()
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Error returning from editor: %s", err))
+	}
+	if !ps.Success() {
+		return "", errors.New("Editor returned non-zero exit status")
+	}
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("Could not read message file: %s", err))
+	}
+	msg := string(data)
+	if commentChar != "" {
+		msg = StripComments(msg, commentChar)
+	}
+	return msg, nil
+}
+
+// EditMessage opens the editor on initial and returns the title entered on
+// the first line and the body below it. Lines starting with commentChar,
+// if it is not empty, are dropped.
+func EditMessage(initial, commentChar string) (title, body string, err error) {
+	msg, err := EditText(initial, commentChar)
+	if err != nil {
+		return "", "", err
+	}
+	return SplitMessage(msg)
+}
+
+// SplitMessage splits a message into its first line and the rest.
+func SplitMessage(msg string) (title, body string, err error) {
+	pieces := strings.SplitN(msg, "\n", 2)
+	if len(pieces) == 0 || len(pieces[0]) == 0 {
+		return "", "", errors.New("No title in description")
+	}
+	title = pieces[0]
+	body = ""
+	if len(pieces) > 1 && len(pieces[1]) > 0 {
+		body = strings.TrimSpace(pieces[1])
+	}
+
+	return title, body, nil
+}
+
+// CommentChar returns git's core.commentChar, which defaults to "#".
+func CommentChar() string {
+	data, err := exec.Command("git", "config", "core.commentChar").Output()
+	if char := strings.TrimSpace(string(data)); err == nil && char != "" && char != "auto" {
+		return char
+	}
+	return "#"
+}
+
+// StripComments removes lines starting with commentChar and leading blank
+// lines, as git does for commit messages.
+func StripComments(msg, commentChar string) string {
+	var lines []string
+	for _, line := range strings.Split(msg, "\n") {
+		if !strings.HasPrefix(line, commentChar) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimLeft(strings.Join(lines, "\n"), "\n")
+}
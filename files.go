@@ -0,0 +1,95 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// PullRequestFile is a file changed by a pull request.
+type PullRequestFile struct {
+	SHA      string
+	Filename string
+	// Status is "added", "removed", "modified", "renamed", "copied",
+	// "changed" or "unchanged".
+	Status    string
+	Additions int
+	Deletions int
+	Changes   int
+	// Patch is the file's part of the unified diff. GitHub omits it for
+	// binary files and very large diffs.
+	Patch string
+	// PreviousFilename is set for renamed files.
+	PreviousFilename string `json:"previous_filename"`
+	BlobUrl          string `json:"blob_url"`
+	RawUrl           string `json:"raw_url"`
+}
+
+// ListPullRequestFiles calls fn with each page of files changed by a pull
+// request. GitHub lists at most 3000 files.
+func (c *ApiClient) ListPullRequestFiles(ctx context.Context, user, project string, pull int, opts *ListOptions, fn func([]PullRequestFile) error) error {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/files",
+		user,
+		project,
+		pull)
+	return c.eachPage(ctx, url, opts, func(page []json.RawMessage) error {
+		var files []PullRequestFile
+		if err := decodePage(url, page, &files); err != nil {
+			return err
+		}
+		return fn(files)
+	})
+}
+
+// GetPullRequestFiles returns the files changed by a pull request from
+// every page.
+func (c *ApiClient) GetPullRequestFiles(ctx context.Context, user, project string, pull int, opts *ListOptions) ([]PullRequestFile, error) {
+	var files []PullRequestFile
+	err := c.ListPullRequestFiles(ctx, user, project, pull, opts, func(page []PullRequestFile) error {
+		files = append(files, page...)
+		return nil
+	})
+	return files, err
+}
+
+// GetPullRequestDiff returns the unified diff of a pull request.
+func (c *ApiClient) GetPullRequestDiff(ctx context.Context, user, project string, pull int) (string, error) {
+	return c.getPullRequestRaw(ctx, user, project, pull, DiffMediaType)
+}
+
+// GetPullRequestPatch returns the commits of a pull request as patches.
+func (c *ApiClient) GetPullRequestPatch(ctx context.Context, user, project string, pull int) (string, error) {
+	return c.getPullRequestRaw(ctx, user, project, pull, PatchMediaType)
+}
+
+func (c *ApiClient) getPullRequestRaw(ctx context.Context, user, project string, pull int, mediaType string) (string, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d",
+		user,
+		project,
+		pull)
+	body, err := c.getRaw(ctx, url, mediaType)
+	return string(body), err
+}
+
+// GetCompareDiff returns the unified diff between two commits, branches or
+// tags. head may be given as "owner:branch" to compare across forks.
+func (c *ApiClient) GetCompareDiff(ctx context.Context, user, project, base, head string) (string, error) {
+	return c.getCompareRaw(ctx, user, project, base, head, DiffMediaType)
+}
+
+// GetComparePatch returns the commits from base to head as patches.
+func (c *ApiClient) GetComparePatch(ctx context.Context, user, project, base, head string) (string, error) {
+	return c.getCompareRaw(ctx, user, project, base, head, PatchMediaType)
+}
+
+func (c *ApiClient) getCompareRaw(ctx context.Context, user, project, base, head, mediaType string) (string, error) {
+	url := c.url(
+		"repos/%s/%s/compare/%s...%s",
+		user,
+		project,
+		base,
+		head)
+	body, err := c.getRaw(ctx, url, mediaType)
+	return string(body), err
+}
@@ -7,25 +7,65 @@ import (
 	"strings"
 )
 
+// EnterpriseHosts lists GitHub Enterprise Server hostnames that are
+// recognized in remote URLs in addition to github.com. Hosts listed in the
+// multi-valued git config key github.host are also recognized.
+var EnterpriseHosts []string
+
 var repoRE *regexp.Regexp
 
 func init() {
 	var err error
-	repoRE, err = regexp.Compile("github\\.com/([^/]+)/([^/]+?)(?:\\.git)?$")
+	// Matches https://host/user/repo.git, git@host:user/repo.git and
+	// ssh://git@host:port/user/repo.git.
+	repoRE, err = regexp.Compile("^(?:[a-z+]+://)?(?:[^@/]+@)?([^/:]+)(?::[0-9]*)?[:/]([^/]+)/([^/]+?)(?:\\.git)?/?$")
 	if err != nil {
 		panic(err)
 	}
 }
 
-func GetUserAndRepo() (string, string, error) {
-	data, err := exec.Command("git", "config", "remote.origin.url").Output()
+// Remote describes the GitHub repository that a git remote points to.
+type Remote struct {
+	Host, User, Repo string
+}
+
+func knownHosts() []string {
+	hosts := append([]string{"github.com"}, EnterpriseHosts...)
+	data, err := exec.Command("git", "config", "--get-all", "github.host").Output()
+	if err == nil {
+		hosts = append(hosts, strings.Fields(string(data))...)
+	}
+	return hosts
+}
+
+// GetRemote parses the URL of remote.origin.url.
+func GetRemote() (*Remote, error) {
+	return GetRemoteByName("origin")
+}
+
+// GetRemoteByName parses the URL of the named git remote.
+func GetRemoteByName(name string) (*Remote, error) {
+	key := "remote." + name + ".url"
+	data, err := exec.Command("git", "config", key).Output()
 	if err != nil {
-		return "", "", errors.New("'git config remote.origin.url' failed")
+		return nil, errors.New("'git config " + key + "' failed")
 	}
 	url := strings.TrimSpace(string(data))
 	matches := repoRE.FindStringSubmatch(url)
-	if matches == nil {
-		return "", "", errors.New("Could not understand remote origin url: " + url)
+	if matches != nil {
+		for _, host := range knownHosts() {
+			if strings.EqualFold(matches[1], host) {
+				return &Remote{Host: host, User: matches[2], Repo: matches[3]}, nil
+			}
+		}
+	}
+	return nil, errors.New("Could not understand remote " + name + " url: " + url)
+}
+
+func GetUserAndRepo() (string, string, error) {
+	remote, err := GetRemote()
+	if err != nil {
+		return "", "", err
 	}
-	return matches[1], matches[2], nil
+	return remote.User, remote.Repo, nil
 }
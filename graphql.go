@@ -0,0 +1,76 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// GraphQLError is returned by GraphQL when the response contains errors.
+type GraphQLError struct {
+	Errors []Error
+}
+
+func (e *GraphQLError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "GraphQL: " + strings.Join(msgs, "; ")
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage
+	Errors []Error
+}
+
+// graphQLURL returns the GraphQL endpoint that corresponds to BaseURL. On
+// GitHub Enterprise Server it lives at /api/graphql rather than under
+// /api/v3.
+func (c *ApiClient) graphQLURL() string {
+	url := c.url("graphql")
+	return strings.Replace(url, "/api/v3/graphql", "/api/graphql", 1)
+}
+
+// GraphQL runs a query or mutation and decodes its "data" into v, which may
+// be nil. Some operations, such as marking a draft pull request ready for
+// review, are only available through GraphQL.
+func (c *ApiClient) GraphQL(ctx context.Context, query string, variables map[string]interface{}, v interface{}) error {
+	url := c.graphQLURL()
+	var resp graphQLResponse
+	if err := c.post(ctx, url, &graphQLRequest{Query: query, Variables: variables}, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return &GraphQLError{Errors: resp.Errors}
+	}
+	if v == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	return decode(url, resp.Data, v)
+}
+
+// MarkPullRequestReadyForReview takes a pull request out of draft. id is the
+// pull request's NodeId.
+func (c *ApiClient) MarkPullRequestReadyForReview(ctx context.Context, id string) error {
+	return c.GraphQL(ctx, `mutation($id: ID!) {
+  markPullRequestReadyForReview(input: {pullRequestId: $id}) {
+    pullRequest { isDraft }
+  }
+}`, map[string]interface{}{"id": id}, nil)
+}
+
+// ConvertPullRequestToDraft puts a pull request back into draft. id is the
+// pull request's NodeId.
+func (c *ApiClient) ConvertPullRequestToDraft(ctx context.Context, id string) error {
+	return c.GraphQL(ctx, `mutation($id: ID!) {
+  convertPullRequestToDraft(input: {pullRequestId: $id}) {
+    pullRequest { isDraft }
+  }
+}`, map[string]interface{}{"id": id}, nil)
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var debug = flag.Bool("d", false, "show debug output for network requests")
+
+var titleText = flag.String("title", "", "issue title; skips the editor")
+
+var bodyText = flag.String("body", "", "issue description, or comment text; skips the editor")
+
+var labels = flag.String("l", "", "comma-separated list of labels to add, or to filter by")
+
+var assignees = flag.String("a", "", "comma-separated list of users to assign, or the assignee to filter by")
+
+var milestone = flag.String("m", "", "milestone to set, by title or number")
+
+var state = flag.String("state", "open", "list issues that are open, closed or all")
+
+var since = flag.String("since", "", "list issues updated since this date (YYYY-MM-DD or RFC 3339)")
+
+var query = flag.String("q", "", "list issues matching this search query instead")
+
+var limit = flag.Int("limit", 30, "maximum number of issues to list (0 for all)")
+
+var reason = flag.String("reason", "", "reason for closing: completed or not_planned")
+
+var c *github.ApiClient
+
+var user, repo string
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, strings.TrimPrefix(item, "@"))
+		}
+	}
+	return items
+}
+
+// newIssueRequest checks -l, -a and -m against the repository so that
+// mistakes are caught before the issue is created.
+func newIssueRequest(ctx context.Context) (*github.IssueRequest, error) {
+	req := &github.IssueRequest{}
+	if names := splitList(*labels); len(names) > 0 {
+		resolved, err := c.ResolveLabels(ctx, user, repo, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, label := range resolved {
+			req.Labels = append(req.Labels, label.Name)
+		}
+	}
+	for _, login := range splitList(*assignees) {
+		ok, err := c.IsAssignable(ctx, user, repo, login)
+		if err != nil {
+			return nil, fmt.Errorf("error checking assignee %s: %w", login, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s cannot be assigned in %s/%s", login, user, repo)
+		}
+		req.Assignees = append(req.Assignees, login)
+	}
+	if *milestone != "" {
+		m, err := c.FindMilestone(ctx, user, repo, *milestone)
+		if err != nil {
+			return nil, err
+		}
+		req.Milestone = m.Number
+	}
+	return req, nil
+}
+
+func create(ctx context.Context) error {
+	req, err := newIssueRequest(ctx)
+	if err != nil {
+		return err
+	}
+	if *titleText != "" {
+		req.Title, req.Body = *titleText, *bodyText
+	} else {
+		commentChar := github.CommentChar()
+		msg := *bodyText + fmt.Sprintf("\n\n"+
+			"%[1]s Please enter the issue title on the first line and its\n"+
+			"%[1]s description below. Lines starting with '%[1]s' will be ignored.\n", commentChar)
+		if *bodyText != "" {
+			msg = "\n\n" + msg
+		}
+		if req.Title, req.Body, err = github.EditMessage(msg, commentChar); err != nil {
+			return err
+		}
+	}
+	issue, err := c.CreateIssue(ctx, user, repo, req)
+	if err != nil {
+		return fmt.Errorf("error creating issue: %w", err)
+	}
+	fmt.Printf("%s\n", issue.HtmlUrl)
+	return nil
+}
+
+func printIssueLine(issue github.Issue) {
+	var names []string
+	for _, label := range issue.Labels {
+		names = append(names, label.Name)
+	}
+	fmt.Printf("#%d\t%s\t%s", issue.Number, issue.State, issue.Title)
+	if len(names) > 0 {
+		fmt.Printf("\t(%s)", strings.Join(names, ", "))
+	}
+	fmt.Println()
+}
+
+func list(ctx context.Context) error {
+	opts := &github.ListOptions{PerPage: 100}
+	// Pull requests are issues too, but are listed by pull-request, so
+	// they don't count towards -limit.
+	printed := 0
+	fn := func(page []github.Issue) error {
+		for _, issue := range page {
+			if issue.IsPullRequest() {
+				continue
+			}
+			printIssueLine(issue)
+			if printed++; *limit > 0 && printed == *limit {
+				return github.ErrStopPaging
+			}
+		}
+		return nil
+	}
+	if *query != "" {
+		q := fmt.Sprintf("repo:%s/%s is:issue %s", user, repo, *query)
+		if *state != "all" {
+			q += " state:" + *state
+		}
+		return c.SearchIssues(ctx, q, opts, fn)
+	}
+	filter := &github.IssueFilter{State: *state, Labels: splitList(*labels), Assignee: *assignees}
+	if *since != "" {
+		t, err := time.Parse("2006-01-02", *since)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, *since); err != nil {
+				return errors.New("invalid date for -since: " + *since)
+			}
+		}
+		filter.Since = t
+	}
+	return c.ListIssues(ctx, user, repo, filter, opts, fn)
+}
+
+func view(ctx context.Context, number int) error {
+	issue, err := c.GetIssue(ctx, user, repo, number)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("#%d %s\n", issue.Number, issue.Title)
+	status := issue.State
+	if issue.StateReason != "" && issue.State == "closed" {
+		status += " as " + strings.Replace(issue.StateReason, "_", " ", -1)
+	}
+	fmt.Printf("%s, opened by %s at %s\n", status, issue.User.Login, issue.Created)
+	fmt.Printf("%s\n", issue.HtmlUrl)
+	if len(issue.Labels) > 0 {
+		var names []string
+		for _, label := range issue.Labels {
+			names = append(names, label.Name)
+		}
+		fmt.Printf("Labels: %s\n", strings.Join(names, ", "))
+	}
+	if len(issue.Assignees) > 0 {
+		var logins []string
+		for _, u := range issue.Assignees {
+			logins = append(logins, u.Login)
+		}
+		fmt.Printf("Assignees: %s\n", strings.Join(logins, ", "))
+	}
+	if issue.Milestone != nil {
+		fmt.Printf("Milestone: %s\n", issue.Milestone.Title)
+	}
+	if issue.Body != "" {
+		fmt.Printf("\n%s\n", issue.Body)
+	}
+	if issue.Comments == 0 {
+		return nil
+	}
+	comments, err := c.GetIssueComments(ctx, user, repo, number, nil)
+	if err != nil {
+		return fmt.Errorf("error fetching comments: %w", err)
+	}
+	for _, comment := range comments {
+		fmt.Printf("\n%s at %s:\n\t%s\n", comment.User.Login, comment.Created,
+			strings.Replace(comment.Body, "\n", "\n\t", -1))
+	}
+	return nil
+}
+
+// getCommentText returns -body, or asks for the comment in the editor.
+func getCommentText() (string, error) {
+	if *bodyText != "" {
+		return *bodyText, nil
+	}
+	commentChar := github.CommentChar()
+	text, err := github.EditText(fmt.Sprintf("\n\n"+
+		"%[1]s Please enter your comment. Lines starting with '%[1]s' will be ignored.\n", commentChar), commentChar)
+	if err != nil {
+		return "", err
+	}
+	if text = strings.TrimSpace(text); text == "" {
+		return "", errors.New("Empty comment")
+	}
+	return text, nil
+}
+
+func comment(ctx context.Context, number int) error {
+	text, err := getCommentText()
+	if err != nil {
+		return err
+	}
+	if _, err := c.CommentOnIssue(ctx, user, repo, number, text); err != nil {
+		return fmt.Errorf("error commenting on #%d: %w", number, err)
+	}
+	return nil
+}
+
+func closeIssue(ctx context.Context, number int) error {
+	switch *reason {
+	case "", "completed", "not_planned":
+	default:
+		return errors.New("invalid reason: " + *reason)
+	}
+	if *bodyText != "" {
+		if _, err := c.CommentOnIssue(ctx, user, repo, number, *bodyText); err != nil {
+			return fmt.Errorf("error commenting on #%d: %w", number, err)
+		}
+	}
+	issue, err := c.CloseIssue(ctx, user, repo, number, *reason)
+	if err != nil {
+		return fmt.Errorf("error closing #%d: %w", number, err)
+	}
+	fmt.Printf("%s\n", issue.HtmlUrl)
+	return nil
+}
+
+func showError(err error) {
+	fmt.Printf("Error: %s\n", err)
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) && apiErr.DocumentationURL != "" {
+		fmt.Printf("See %s\n", apiErr.DocumentationURL)
+	}
+	os.Exit(1)
+}
+
+func usageAndQuit() {
+	flag.Usage()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s <command> [options] [issue]\n", os.Args[0])
+		fmt.Fprint(os.Stderr, `
+Commands:
+  create   open an issue; -title skips the editor, -l, -a and -m set labels,
+           assignees and milestone
+  list     list issues, filtered by -state, -l, -a and -since, or matching -q
+  view     show an issue and its comments
+  comment  comment on an issue; -body skips the editor
+  close    close an issue, with an optional -reason and closing comment -body
+
+Options:
+`)
+		flag.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		usageAndQuit()
+	}
+	command := os.Args[1]
+	flag.CommandLine.Parse(os.Args[2:])
+
+	var number int
+	switch command {
+	case "create", "list":
+		if flag.NArg() != 0 {
+			usageAndQuit()
+		}
+	case "view", "comment", "close":
+		if flag.NArg() != 1 {
+			usageAndQuit()
+		}
+		var err error
+		if number, err = strconv.Atoi(flag.Arg(0)); err != nil {
+			showError(errors.New("invalid issue number: " + flag.Arg(0)))
+		}
+	default:
+		usageAndQuit()
+	}
+
+	// Cancel in-flight requests on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	remote, err := github.GetRemote()
+	if err != nil {
+		showError(err)
+	}
+	user, repo = remote.User, remote.Repo
+
+	c, err = github.ApiClientForHost(remote.Host)
+	if err != nil {
+		showError(err)
+	}
+	c.Debug = *debug
+
+	switch command {
+	case "create":
+		err = create(ctx)
+	case "list":
+		err = list(ctx)
+	case "view":
+		err = view(ctx, number)
+	case "comment":
+		err = comment(ctx, number)
+	case "close":
+		err = closeIssue(ctx, number)
+	}
+	if err != nil {
+		showError(err)
+	}
+}
@@ -0,0 +1,222 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+type Issue struct {
+	Id     int64 `json:"id"`
+	Number int
+	NodeId string `json:"node_id"`
+	Title  string
+	Body   string
+	// State is "open" or "closed".
+	State string
+	// StateReason is "completed", "not_planned" or "reopened", if set.
+	StateReason string `json:"state_reason"`
+	User        User
+	Labels      []Label
+	Assignees   []User
+	Milestone   *Milestone
+	Comments    int
+	HtmlUrl     string `json:"html_url"`
+	Created     string `json:"created_at"`
+	Updated     string `json:"updated_at"`
+	Closed      string `json:"closed_at"`
+	// PullRequest is set if the issue is a pull request.
+	PullRequest *struct {
+		Url string
+	} `json:"pull_request"`
+}
+
+// IsPullRequest reports whether the issue is a pull request, which GitHub
+// includes when listing issues.
+func (i *Issue) IsPullRequest() bool {
+	return i.PullRequest != nil
+}
+
+// IssueFilter selects the issues returned by ListIssues. The zero value
+// lists open issues.
+type IssueFilter struct {
+	// State is "open", "closed" or "all".
+	State string
+	// Labels lists labels that every issue must have.
+	Labels []string
+	// Assignee is a login, "none" for unassigned issues, or "*" for
+	// issues assigned to anyone.
+	Assignee  string
+	Creator   string
+	Mentioned string
+	// Since, if not zero, selects issues updated at or after this time.
+	Since time.Time
+}
+
+func (f *IssueFilter) query() string {
+	if f == nil {
+		return ""
+	}
+	q := neturl.Values{}
+	if f.State != "" {
+		q.Set("state", f.State)
+	}
+	if len(f.Labels) > 0 {
+		q.Set("labels", strings.Join(f.Labels, ","))
+	}
+	if f.Assignee != "" {
+		q.Set("assignee", f.Assignee)
+	}
+	if f.Creator != "" {
+		q.Set("creator", f.Creator)
+	}
+	if f.Mentioned != "" {
+		q.Set("mentioned", f.Mentioned)
+	}
+	if !f.Since.IsZero() {
+		q.Set("since", f.Since.UTC().Format(time.RFC3339))
+	}
+	return q.Encode()
+}
+
+// IssueRequest creates an issue. Milestone is a milestone number.
+type IssueRequest struct {
+	Title     string   `json:"title"`
+	Body      string   `json:"body,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+	Milestone int      `json:"milestone,omitempty"`
+}
+
+// IssueUpdate holds the fields to change in UpdateIssue. Nil fields are
+// left unchanged.
+type IssueUpdate struct {
+	Title       *string `json:"title,omitempty"`
+	Body        *string `json:"body,omitempty"`
+	State       *string `json:"state,omitempty"`
+	StateReason *string `json:"state_reason,omitempty"`
+}
+
+// ListIssues calls fn with each page of issues in a repository that match
+// filter, which may be nil. Pull requests are included; see
+// Issue.IsPullRequest.
+func (c *ApiClient) ListIssues(ctx context.Context, user, project string, filter *IssueFilter, opts *ListOptions, fn func([]Issue) error) error {
+	url := c.url(
+		"repos/%s/%s/issues",
+		user,
+		project)
+	if q := filter.query(); q != "" {
+		url += "?" + q
+	}
+	return c.eachIssue(ctx, url, "", opts, fn)
+}
+
+// GetIssues returns the issues matching filter from every page.
+func (c *ApiClient) GetIssues(ctx context.Context, user, project string, filter *IssueFilter, opts *ListOptions) ([]Issue, error) {
+	var issues []Issue
+	err := c.ListIssues(ctx, user, project, filter, opts, func(page []Issue) error {
+		issues = append(issues, page...)
+		return nil
+	})
+	return issues, err
+}
+
+// SearchIssues calls fn with each page of issues and pull requests matching
+// a search query, such as "repo:owner/name is:issue crash".
+func (c *ApiClient) SearchIssues(ctx context.Context, query string, opts *ListOptions, fn func([]Issue) error) error {
+	url := c.url("search/issues?q=%s", neturl.QueryEscape(query))
+	return c.eachIssue(ctx, url, "items", opts, fn)
+}
+
+func (c *ApiClient) eachIssue(ctx context.Context, url, key string, opts *ListOptions, fn func([]Issue) error) error {
+	return c.eachWrappedPage(ctx, url, key, opts, func(page []json.RawMessage) error {
+		var issues []Issue
+		if err := decodePage(url, page, &issues); err != nil {
+			return err
+		}
+		return fn(issues)
+	})
+}
+
+func (c *ApiClient) GetIssue(ctx context.Context, user, project string, number int) (Issue, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d",
+		user,
+		project,
+		number)
+	var issue Issue
+	err := c.get(ctx, url, &issue)
+	return issue, err
+}
+
+func (c *ApiClient) CreateIssue(ctx context.Context, user, project string, req *IssueRequest) (Issue, error) {
+	url := c.url(
+		"repos/%s/%s/issues",
+		user,
+		project)
+	var issue Issue
+	err := c.post(ctx, url, req, &issue)
+	return issue, err
+}
+
+// UpdateIssue changes the fields of an issue that are set in update.
+func (c *ApiClient) UpdateIssue(ctx context.Context, user, project string, number int, update *IssueUpdate) (Issue, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d",
+		user,
+		project,
+		number)
+	var issue Issue
+	err := c.do(ctx, "PATCH", url, update, &issue)
+	return issue, err
+}
+
+// CloseIssue closes an issue. reason is "completed", "not_planned" or
+// empty.
+func (c *ApiClient) CloseIssue(ctx context.Context, user, project string, number int, reason string) (Issue, error) {
+	state := "closed"
+	update := &IssueUpdate{State: &state}
+	if reason != "" {
+		update.StateReason = &reason
+	}
+	return c.UpdateIssue(ctx, user, project, number, update)
+}
+
+func (c *ApiClient) ReopenIssue(ctx context.Context, user, project string, number int) (Issue, error) {
+	state := "open"
+	return c.UpdateIssue(ctx, user, project, number, &IssueUpdate{State: &state})
+}
+
+// ListIssueComments calls fn with each page of comments on an issue.
+func (c *ApiClient) ListIssueComments(ctx context.Context, user, project string, number int, opts *ListOptions, fn func(CommentList) error) error {
+	url := c.url(
+		"repos/%s/%s/issues/%d/comments",
+		user,
+		project,
+		number)
+	return c.eachComment(ctx, url, opts, fn)
+}
+
+// GetIssueComments returns the comments on an issue from every page.
+func (c *ApiClient) GetIssueComments(ctx context.Context, user, project string, number int, opts *ListOptions) (CommentList, error) {
+	var comments CommentList
+	err := c.ListIssueComments(ctx, user, project, number, opts, func(page CommentList) error {
+		comments = append(comments, page...)
+		return nil
+	})
+	return comments, err
+}
+
+func (c *ApiClient) CommentOnIssue(ctx context.Context, user, project string, number int, body string) (Comment, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d/comments",
+		user,
+		project,
+		number)
+	req := &BodyOnlyComment{Body: body}
+	var comment Comment
+	err := c.post(ctx, url, req, &comment)
+	return comment, err
+}
@@ -0,0 +1,120 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type Label struct {
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type labelsRequest struct {
+	Labels []string `json:"labels"`
+}
+
+// ListLabels calls fn with each page of labels defined in a repository.
+func (c *ApiClient) ListLabels(ctx context.Context, user, project string, opts *ListOptions, fn func([]Label) error) error {
+	url := c.url(
+		"repos/%s/%s/labels",
+		user,
+		project)
+	return c.eachLabelPage(ctx, url, opts, fn)
+}
+
+// GetLabels returns the labels defined in a repository from every page.
+func (c *ApiClient) GetLabels(ctx context.Context, user, project string, opts *ListOptions) ([]Label, error) {
+	var labels []Label
+	err := c.ListLabels(ctx, user, project, opts, func(page []Label) error {
+		labels = append(labels, page...)
+		return nil
+	})
+	return labels, err
+}
+
+// GetIssueLabels returns the labels on an issue or pull request.
+func (c *ApiClient) GetIssueLabels(ctx context.Context, user, project string, issue int) ([]Label, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d/labels",
+		user,
+		project,
+		issue)
+	var labels []Label
+	err := c.eachLabelPage(ctx, url, nil, func(page []Label) error {
+		labels = append(labels, page...)
+		return nil
+	})
+	return labels, err
+}
+
+func (c *ApiClient) eachLabelPage(ctx context.Context, url string, opts *ListOptions, fn func([]Label) error) error {
+	return c.eachPage(ctx, url, opts, func(page []json.RawMessage) error {
+		var labels []Label
+		if err := decodePage(url, page, &labels); err != nil {
+			return err
+		}
+		return fn(labels)
+	})
+}
+
+// AddLabels adds labels to an issue or pull request, and returns all of its
+// labels.
+func (c *ApiClient) AddLabels(ctx context.Context, user, project string, issue int, labels []string) ([]Label, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d/labels",
+		user,
+		project,
+		issue)
+	var result []Label
+	err := c.post(ctx, url, &labelsRequest{Labels: labels}, &result)
+	return result, err
+}
+
+// SetLabels replaces the labels on an issue or pull request. An empty list
+// removes them all.
+func (c *ApiClient) SetLabels(ctx context.Context, user, project string, issue int, labels []string) ([]Label, error) {
+	url := c.url(
+		"repos/%s/%s/issues/%d/labels",
+		user,
+		project,
+		issue)
+	if labels == nil {
+		labels = []string{}
+	}
+	var result []Label
+	err := c.do(ctx, "PUT", url, &labelsRequest{Labels: labels}, &result)
+	return result, err
+}
+
+// ResolveLabels maps names to the labels defined in a repository, ignoring
+// case as GitHub does. It fails if any name is not defined, so that typos
+// are caught before anything is changed.
+func (c *ApiClient) ResolveLabels(ctx context.Context, user, project string, names []string) ([]Label, error) {
+	defined, err := c.GetLabels(ctx, user, project, &ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]Label)
+	for _, label := range defined {
+		byName[strings.ToLower(label.Name)] = label
+	}
+	var labels []Label
+	var unknown []string
+	for _, name := range names {
+		label, ok := byName[strings.ToLower(name)]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		labels = append(labels, label)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown labels in %s/%s: %s", user, project, strings.Join(unknown, ", "))
+	}
+	return labels, nil
+}
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"context"
+)
+
+// MergeOptions controls how MergePullRequest merges.
+type MergeOptions struct {
+	// MergeMethod is "merge", "squash" or "rebase". If empty, the
+	// repository's default is used.
+	MergeMethod string `json:"merge_method,omitempty"`
+	// SHA, if set, makes the merge fail unless it is still the head of
+	// the pull request.
+	SHA string `json:"sha,omitempty"`
+	// CommitTitle and CommitMessage override the merge commit's title and
+	// message.
+	CommitTitle   string `json:"commit_title,omitempty"`
+	CommitMessage string `json:"commit_message,omitempty"`
+}
+
+// MergeResult is the response from MergePullRequest.
+type MergeResult struct {
+	SHA     string
+	Merged  bool
+	Message string
+}
+
+type Branch struct {
+	Name       string
+	Commit     Commit
+	Protected  bool
+	Protection BranchProtection
+}
+
+type BranchProtection struct {
+	RequiredStatusChecks RequiredStatusChecks `json:"required_status_checks"`
+}
+
+type RequiredStatusChecks struct {
+	Contexts []string
+}
+
+// MergePullRequest merges a pull request. opts may be nil.
+func (c *ApiClient) MergePullRequest(ctx context.Context, user, project string, id int, opts *MergeOptions) (MergeResult, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/merge",
+		user,
+		project,
+		id)
+	if opts == nil {
+		opts = &MergeOptions{}
+	}
+	var result MergeResult
+	err := c.do(ctx, "PUT", url, opts, &result)
+	return result, err
+}
+
+// GetBranch returns a branch, including its protection settings.
+func (c *ApiClient) GetBranch(ctx context.Context, user, project, branch string) (Branch, error) {
+	url := c.url(
+		"repos/%s/%s/branches/%s",
+		user,
+		project,
+		branch)
+	var b Branch
+	err := c.get(ctx, url, &b)
+	return b, err
+}
+
+// DeleteBranch deletes a branch.
+func (c *ApiClient) DeleteBranch(ctx context.Context, user, project, branch string) error {
+	url := c.url(
+		"repos/%s/%s/git/refs/heads/%s",
+		user,
+		project,
+		branch)
+	return c.do(ctx, "DELETE", url, nil, nil)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var debug = flag.Bool("d", false, "show debug output for network requests")
+
+var method = flag.String("method", "", "merge method: merge, squash or rebase (default: the repository's default)")
+
+var title = flag.String("title", "", "title for the merge commit")
+
+var message = flag.String("message", "", "message for the merge commit")
+
+var deleteBranch = flag.Bool("delete-branch", false, "delete the head branch after merging")
+
+var force = flag.Bool("f", false, "merge even if changes were requested or checks are failing")
+
+var c *github.ApiClient
+
+var user, repo string
+
+// getMergeablePullRequest fetches a pull request, waiting briefly for
+// GitHub to finish computing whether it can be merged.
+func getMergeablePullRequest(ctx context.Context, number int) (github.PullRequest, error) {
+	for i := 0; ; i++ {
+		pull, err := c.GetPullRequest(ctx, user, repo, number)
+		if err != nil || pull.Mergeable != nil || pull.State != "open" || i == 5 {
+			return pull, err
+		}
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return pull, ctx.Err()
+		}
+	}
+}
+
+// reviewProblems lists reviewers whose latest review requests changes.
+func reviewProblems(ctx context.Context, pull github.PullRequest) ([]string, error) {
+	reviews, err := c.GetReviews(ctx, user, repo, pull.Number, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching reviews: %w", err)
+	}
+	latest := make(map[string]string)
+	var order []string
+	for _, review := range reviews {
+		// Comments don't change a reviewer's verdict.
+		if review.State == "COMMENTED" || review.State == "PENDING" {
+			continue
+		}
+		if _, ok := latest[review.User.Login]; !ok {
+			order = append(order, review.User.Login)
+		}
+		latest[review.User.Login] = review.State
+	}
+	var problems []string
+	approved := false
+	for _, login := range order {
+		switch latest[login] {
+		case "CHANGES_REQUESTED":
+			problems = append(problems, login+" requested changes")
+		case "APPROVED":
+			approved = true
+			fmt.Printf("Approved by %s\n", login)
+		}
+	}
+	if !approved {
+		fmt.Println("No approving reviews")
+	}
+	return problems, nil
+}
+
+// statusProblems lists the required status checks on the base branch that
+// have not succeeded on the head commit. A required context is satisfied by
+// either a commit status or a check run, such as a GitHub Actions job, of
+// that name.
+func statusProblems(ctx context.Context, pull github.PullRequest) ([]string, error) {
+	branch, err := c.GetBranch(ctx, user, repo, pull.Base.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching branch %s: %w", pull.Base.Ref, err)
+	}
+	required := branch.Protection.RequiredStatusChecks.Contexts
+	if !branch.Protected || len(required) == 0 {
+		return nil, nil
+	}
+	status, err := c.GetCombinedStatus(ctx, user, repo, pull.Head.SHA)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching status of %s: %w", pull.Head.SHA, err)
+	}
+	states := make(map[string]string)
+	for _, s := range status.Statuses {
+		states[s.Context] = s.State
+	}
+	runs, err := c.GetCheckRuns(ctx, user, repo, pull.Head.SHA, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching check runs on %s: %w", pull.Head.SHA, err)
+	}
+	for _, run := range runs {
+		// GitHub lists the latest run of each name per app; if a status or
+		// another app's run of the same name passed, that is enough.
+		if states[run.Name] == "success" {
+			continue
+		}
+		state := run.Status
+		if state == "completed" {
+			state = run.Conclusion
+			if state == "neutral" || state == "skipped" {
+				state = "success"
+			}
+		}
+		states[run.Name] = state
+	}
+	var problems []string
+	for _, name := range required {
+		state, ok := states[name]
+		if !ok {
+			state = "missing"
+		}
+		if state != "success" {
+			problems = append(problems, fmt.Sprintf("required check %s is %s", name, state))
+		}
+	}
+	return problems, nil
+}
+
+// checkPullRequest returns an error describing why pull should not be
+// merged, if anything.
+func checkPullRequest(ctx context.Context, pull github.PullRequest) error {
+	if pull.Merged {
+		return fmt.Errorf("#%d is already merged", pull.Number)
+	}
+	if pull.State != "open" {
+		return fmt.Errorf("#%d is %s", pull.Number, pull.State)
+	}
+	if pull.Draft {
+		return fmt.Errorf("#%d is a draft; mark it ready for review first", pull.Number)
+	}
+	if pull.Mergeable == nil {
+		return fmt.Errorf("GitHub has not finished checking whether #%d can be merged; try again", pull.Number)
+	}
+	if !*pull.Mergeable {
+		return fmt.Errorf("#%d has conflicts with %s", pull.Number, pull.Base.Ref)
+	}
+	problems, err := reviewProblems(ctx, pull)
+	if err != nil {
+		return err
+	}
+	// GitHub reports "blocked" when branch protection rules are not
+	// satisfied; find out which.
+	if pull.MergeableState == "blocked" {
+		statuses, err := statusProblems(ctx, pull)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, statuses...)
+		if len(statuses) == 0 {
+			problems = append(problems, "merging is blocked by branch protection rules")
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("#%d cannot be merged:\n  %s", pull.Number, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func merge(ctx context.Context, number int) error {
+	pull, err := getMergeablePullRequest(ctx, number)
+	if err != nil {
+		return fmt.Errorf("error fetching pull request #%d: %w", number, err)
+	}
+	if err := checkPullRequest(ctx, pull); err != nil {
+		if !*force || pull.Merged || pull.State != "open" || pull.Draft {
+			return err
+		}
+		fmt.Printf("Warning: %s\n", err)
+	}
+	// Refuse to merge if new commits were pushed after the checks above.
+	result, err := c.MergePullRequest(ctx, user, repo, number, &github.MergeOptions{
+		MergeMethod:   *method,
+		SHA:           pull.Head.SHA,
+		CommitTitle:   *title,
+		CommitMessage: *message,
+	})
+	if err != nil {
+		return fmt.Errorf("error merging #%d: %w", number, err)
+	}
+	fmt.Printf("Merged #%d as %s\n", number, result.SHA)
+	if *deleteBranch {
+		head := pull.Head.Repo
+		if err := c.DeleteBranch(ctx, head.Owner.Login, head.Name, pull.Head.Ref); err != nil {
+			return fmt.Errorf("error deleting branch %s: %w", pull.Head.Ref, err)
+		}
+		fmt.Printf("Deleted branch %s\n", pull.Head.Ref)
+	}
+	return nil
+}
+
+func showError(err error) {
+	fmt.Printf("Error: %s\n", err)
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) && apiErr.DocumentationURL != "" {
+		fmt.Printf("See %s\n", apiErr.DocumentationURL)
+	}
+	os.Exit(1)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <pull>\n\n", os.Args[0])
+		fmt.Fprint(os.Stderr, "Checks that the pull request can be merged, has no outstanding\n"+
+			"requests for changes and passes its required status checks, then merges it.\n\n")
+		fmt.Fprint(os.Stderr, "Options:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	number, err := strconv.Atoi(flag.Arg(0))
+	if err != nil {
+		showError(errors.New("invalid pull request number: " + flag.Arg(0)))
+	}
+	switch *method {
+	case "", "merge", "squash", "rebase":
+	default:
+		showError(errors.New("invalid merge method: " + *method))
+	}
+
+	// Cancel in-flight requests on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	remote, err := github.GetRemote()
+	if err != nil {
+		showError(err)
+	}
+	user, repo = remote.User, remote.Repo
+
+	c, err = github.ApiClientForHost(remote.Host)
+	if err != nil {
+		showError(err)
+	}
+	c.Debug = *debug
+
+	if err := merge(ctx, number); err != nil {
+		showError(err)
+	}
+}
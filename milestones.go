@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type Milestone struct {
+	Id     int64  `json:"id"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	// State is "open" or "closed".
+	State       string `json:"state"`
+	Description string `json:"description"`
+	DueOn       string `json:"due_on"`
+}
+
+// milestoneRequest sets the milestone of an issue. A nil Milestone clears
+// it.
+type milestoneRequest struct {
+	Milestone *int `json:"milestone"`
+}
+
+// ListMilestones calls fn with each page of milestones in a repository,
+// both open and closed.
+func (c *ApiClient) ListMilestones(ctx context.Context, user, project string, opts *ListOptions, fn func([]Milestone) error) error {
+	url := c.url(
+		"repos/%s/%s/milestones?state=all",
+		user,
+		project)
+	return c.eachPage(ctx, url, opts, func(page []json.RawMessage) error {
+		var milestones []Milestone
+		if err := decodePage(url, page, &milestones); err != nil {
+			return err
+		}
+		return fn(milestones)
+	})
+}
+
+// GetMilestones returns the milestones in a repository from every page.
+func (c *ApiClient) GetMilestones(ctx context.Context, user, project string, opts *ListOptions) ([]Milestone, error) {
+	var milestones []Milestone
+	err := c.ListMilestones(ctx, user, project, opts, func(page []Milestone) error {
+		milestones = append(milestones, page...)
+		return nil
+	})
+	return milestones, err
+}
+
+// FindMilestone returns the milestone whose number or title is name.
+// Titles are matched exactly; an open milestone is preferred over closed
+// ones with the same title.
+func (c *ApiClient) FindMilestone(ctx context.Context, user, project, name string) (Milestone, error) {
+	number, err := strconv.Atoi(name)
+	if err != nil {
+		number = 0
+	}
+	var found *Milestone
+	err = c.ListMilestones(ctx, user, project, &ListOptions{PerPage: 100}, func(page []Milestone) error {
+		for i := range page {
+			m := page[i]
+			if m.Number == number {
+				found = &m
+				return ErrStopPaging
+			}
+			if m.Title == name && (found == nil || found.State != "open") {
+				found = &m
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Milestone{}, err
+	}
+	if found == nil {
+		return Milestone{}, fmt.Errorf("no milestone %s in %s/%s", name, user, project)
+	}
+	return *found, nil
+}
+
+// SetMilestone sets the milestone of an issue or pull request by number.
+// A number of 0 removes the milestone.
+func (c *ApiClient) SetMilestone(ctx context.Context, user, project string, issue, milestone int) error {
+	url := c.url(
+		"repos/%s/%s/issues/%d",
+		user,
+		project,
+		issue)
+	req := &milestoneRequest{}
+	if milestone != 0 {
+		req.Milestone = &milestone
+	}
+	return c.do(ctx, "PATCH", url, req, nil)
+}
@@ -0,0 +1,133 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrStopPaging may be returned by a page callback to stop fetching further
+// pages without reporting an error.
+var ErrStopPaging = errors.New("stop paging")
+
+// ListOptions controls how list endpoints are paginated. A nil *ListOptions
+// fetches every page using GitHub's default page size.
+type ListOptions struct {
+	// PerPage is the number of results requested per page (GitHub allows
+	// at most 100). Zero uses GitHub's default of 30.
+	PerPage int
+	// MaxItems stops pagination once this many results have been
+	// returned. Zero means no limit.
+	MaxItems int
+}
+
+func (o *ListOptions) firstPageURL(rawurl string) (string, error) {
+	if o == nil || o.PerPage <= 0 {
+		return rawurl, nil
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("per_page", strconv.Itoa(o.PerPage))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// eachPage fetches url and every page that follows it via the Link header,
+// calling fn with the raw items of each page.
+func (c *ApiClient) eachPage(ctx context.Context, url string, opts *ListOptions, fn func([]json.RawMessage) error) error {
+	return c.eachWrappedPage(ctx, url, "", opts, fn)
+}
+
+// eachWrappedPage is like eachPage for endpoints that wrap each page's items
+// in an object, under key, alongside a total_count. If key is empty, each
+// page must be a JSON array.
+func (c *ApiClient) eachWrappedPage(ctx context.Context, url, key string, opts *ListOptions, fn func([]json.RawMessage) error) error {
+	url, err := opts.firstPageURL(url)
+	if err != nil {
+		return err
+	}
+	seen := 0
+	for url != "" {
+		body, header, err := c.load(ctx, &Request{Method: "GET", URL: url})
+		if err != nil {
+			return err
+		}
+		page, err := decodeItems(url, body, key)
+		if err != nil {
+			return err
+		}
+		next := nextPageURL(header)
+		if opts != nil && opts.MaxItems > 0 && seen+len(page) >= opts.MaxItems {
+			page = page[:opts.MaxItems-seen]
+			next = ""
+		}
+		seen += len(page)
+		if err := fn(page); err != nil {
+			if err == ErrStopPaging {
+				return nil
+			}
+			return err
+		}
+		url = next
+	}
+	return nil
+}
+
+// decodeItems decodes the items of a page, which is a JSON array if key is
+// empty and otherwise an object with the items under key.
+func decodeItems(url string, body []byte, key string) ([]json.RawMessage, error) {
+	var page []json.RawMessage
+	if key == "" {
+		err := decode(url, body, &page)
+		return page, err
+	}
+	var result map[string]json.RawMessage
+	if err := decode(url, body, &result); err != nil {
+		return nil, err
+	}
+	items, ok := result[key]
+	if !ok {
+		return nil, fmt.Errorf("no %q in response from %s", key, url)
+	}
+	err := decode(url, items, &page)
+	return page, err
+}
+
+// decodePage decodes the raw items of a page into v, which must be a pointer
+// to a slice.
+func decodePage(url string, page []json.RawMessage, v interface{}) error {
+	data, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	return decode(url, data, v)
+}
+
+// nextPageURL returns the rel="next" target of a Link header, or "" if there
+// is none.
+func nextPageURL(header http.Header) string {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		pieces := strings.Split(link, ";")
+		if len(pieces) < 2 {
+			continue
+		}
+		target := strings.TrimSpace(pieces[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range pieces[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+	return ""
+}
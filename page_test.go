@@ -0,0 +1,34 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrappedPages(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Another array alongside the items must not be mistaken for them.
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", "<"+srvURL+r.URL.Path+`?page=2>; rel="next"`)
+			w.Write([]byte(`{"total_count":2,"other":[{"id":9}],"check_runs":[{"id":1}]}`))
+			return
+		}
+		w.Write([]byte(`{"total_count":2,"check_runs":[{"id":2}],"other":[{"id":9}]}`))
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+	c := &ApiClient{BaseURL: srv.URL + "/"}
+	runs, err := c.GetCheckRuns(context.Background(), "o", "r", "abc", nil)
+	if err != nil {
+		t.Fatalf("GetCheckRuns: %v", err)
+	}
+	if len(runs) != 2 || runs[0].Id != 1 || runs[1].Id != 2 {
+		t.Errorf("GetCheckRuns = %+v, want runs 1 and 2", runs)
+	}
+	if _, err := c.GetCheckSuites(context.Background(), "o", "r", "abc", nil); err == nil {
+		t.Error("GetCheckSuites without check_suites in the response: got nil, want an error")
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -8,84 +10,109 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
 )
 
-var pushFirst = flag.Bool("p", false, "push to origin before making pull request")
+var pushFirst = flag.Bool("p", false, "push to the head remote before making pull request")
+
+var baseBranch = flag.String("base", "", "branch to merge into (default: the repository's default branch)")
+
+var targetRemote = flag.String("remote", "origin", "git remote of the repository to open the pull request in")
+
+var headRemote = flag.String("head-remote", "", "git remote the branch is pushed to, if it is a fork (default: -remote)")
 
 var debug = flag.Bool("d", false, "show debug output for network requests")
 
 var issue = flag.Int("i", -1, "existing issue to use instead of opening a new one")
 
-var reviewers = flag.String("r", "", "comma-separated list of reviewers to assign immediately")
+var reviewers = flag.String("r", "", "comma-separated list of reviewers (users or org/team) to request immediately")
 
-func getEditor() (string, error) {
-	data, err := exec.Command("git", "config", "core.editor").Output()
-	if err == nil {
-		return strings.TrimSpace(string(data)), nil
-	}
-	// Editor not set in git config; try environment variables
-	editor := os.Getenv("VISUAL")
-	if editor != "" {
-		return editor, nil
-	}
-	editor = os.Getenv("EDITOR")
-	if editor != "" {
-		return editor, nil
-	}
-	return "vi", nil
-}
+var removeReviewers = flag.String("R", "", "comma-separated list of reviewers (users or org/team) to remove from an existing pull request")
 
-func getCommitMessageFromUser(defaultMessage string) (title, body string, err error) {
-	editor, err := getEditor()
-	if err != nil {
-		return "", "", err
-	}
-	fname := ".git/COMMIT_EDITMSG"
-	err = ioutil.WriteFile(fname, []byte(defaultMessage), 0644)
-	if err != nil {
-		return "", "", err
+var labels = flag.String("l", "", "comma-separated list of labels to add")
+
+var assignees = flag.String("a", "", "comma-separated list of users to assign")
+
+var milestone = flag.String("m", "", "milestone to set, by title or number")
+
+var number = flag.Int("n", 0, "existing pull request to change instead of the one for the current branch")
+
+var update = flag.Bool("update", false, "edit the title and description of an existing pull request")
+
+var closePull = flag.Bool("close", false, "close an existing pull request")
+
+var titleText = flag.String("title", "", "pull request title; skips the editor")
+
+var bodyText = flag.String("body", "", "pull request description")
+
+var bodyFile = flag.String("body-file", "", "read the pull request description from a file, or stdin if -")
+
+var fill = flag.Bool("fill", false, "use the commit messages verbatim as the title and description; skips the editor")
+
+var jsonOutput = flag.Bool("json", false, "print the pull request as JSON")
+
+var templateName = flag.String("template", "", "template from .github/PULL_REQUEST_TEMPLATE/ to start the description from")
+
+var draft = flag.Bool("draft", false, "open the pull request as a draft")
+
+var ready = flag.Bool("ready", false, "mark an existing draft pull request ready for review")
+
+var reopenPull = flag.Bool("reopen", false, "reopen a closed pull request; requires -n")
+
+var wait = flag.Bool("wait", false, "with status, wait until every check has finished")
+
+var c *github.ApiClient
+
+var user, repo string
+
+// headOwner owns the repository that the branch is pushed to.
+var headOwner string
+
+// getBody returns the description given by -body or -body-file, and whether
+// either was set.
+func getBody() (string, bool, error) {
+	if *bodyFile == "" {
+		return *bodyText, *bodyText != "", nil
 	}
-	editorPath, err := exec.LookPath(editor)
-	if err != nil {
-		return "", "", err
+	if *bodyText != "" {
+		return "", false, errors.New("-body and -body-file cannot be used together")
 	}
-	pa := &os.ProcAttr{Env: os.Environ(), Files: []*os.File{os.Stdin, os.Stdout, os.Stderr}}
-	p, err := os.StartProcess(editorPath, []string{editorPath, fname}, pa)
-	if err != nil {
-		return "", "", errors.New(fmt.Sprintf("Could not start editor '%s': %s", editorPath, err))
+	var data []byte
+	var err error
+	if *bodyFile == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(*bodyFile)
 	}
-	ps, err := p.Wait()
 	if err != nil {
-		return "", "", errors.New(fmt.Sprintf("Error returning from editor: %s", err))
+		return "", false, fmt.Errorf("Could not read description: %s", err)
 	}
-	if !ps.Success() {
-		return "", "", errors.New("Editor returned non-zero exit status")
+	return strings.TrimSpace(string(data)), true, nil
+}
+
+// printPullRequest reports the result to the user, or to downstream tools
+// if -json is set.
+func printPullRequest(pull github.PullRequest) error {
+	if !*jsonOutput {
+		fmt.Printf("%s\n", pull.IssueUrl)
+		return nil
 	}
-	data, err := ioutil.ReadFile(fname)
+	data, err := json.MarshalIndent(pull, "", "  ")
 	if err != nil {
-		return "", "", errors.New(fmt.Sprintf("Could not read commit message file: %s", err))
-	}
-	pieces := strings.SplitN(string(data), "\n", 2)
-	if len(pieces) == 0 || len(pieces[0]) == 0 {
-		return "", "", errors.New("No title in description")
-	}
-	title = pieces[0]
-	body = ""
-	if len(pieces) > 1 && len(pieces[1]) > 0 {
-		body = strings.TrimSpace(pieces[1])
+		return err
 	}
-
-	return title, body, nil
+	fmt.Printf("%s\n", data)
+	return nil
 }
 
-func getRevList(branch string) ([]string, error) {
+func getRevList(upstream, branch string) ([]string, error) {
 	output, err := exec.Command(
-		"git", "log", "--oneline", "origin/master..."+branch).Output()
+		"git", "log", "--oneline", upstream+"..."+branch).Output()
 	if err != nil {
 		return nil, errors.New(
 			fmt.Sprintf(
-				"Error running 'git log --oneline origin/master...%s'\n", branch))
+				"Error running 'git log --oneline %s...%s'\n", upstream, branch))
 	}
 	rev_list := strings.TrimSpace(string(output))
 	if rev_list == "" {
@@ -102,10 +129,17 @@ func getRevList(branch string) ([]string, error) {
 	return revs, nil
 }
 
-func getCommitMessage(branch string) (string, error) {
-	data, err := exec.Command("git", "show", "-s", "--format=%w(78,0,0)%s%n%+b", "master.."+branch).Output()
+// getCommitMessage returns the messages of the commits on branch. With wrap,
+// they are wrapped to 78 columns for editing; otherwise they are unchanged,
+// as -fill uses them.
+func getCommitMessage(upstream, branch string, wrap bool) (string, error) {
+	format := "%s%n%+b"
+	if wrap {
+		format = "%w(78,0,0)" + format
+	}
+	data, err := exec.Command("git", "show", "-s", "--format="+format, upstream+".."+branch).Output()
 	if err != nil {
-		return "", errors.New("'git show -s --format=\"%w(78,0,0)%s%n%+b\" master.." + branch + "' failed")
+		return "", errors.New("'git show -s --format=\"" + format + "\" " + upstream + ".." + branch + "' failed")
 	}
 	return strings.TrimSpace(string(data)), nil
 }
@@ -124,105 +158,458 @@ func getBranch() (string, error) {
 	return "", errors.New("Could not determine current branch")
 }
 
-func push(branch string) error {
-	fmt.Println("Pushing to origin...")
-	output, err := exec.Command("git", "push", "origin", branch).CombinedOutput()
+func push(remote, branch string) error {
+	// Keep stdout clean for -json.
+	fmt.Fprintf(os.Stderr, "Pushing to %s...\n", remote)
+	output, err := exec.Command("git", "push", remote, branch).CombinedOutput()
 	if err != nil {
 		return errors.New(fmt.Sprintf("Error pushing:\n%s", output))
 	}
-	fmt.Print(string(output))
+	fmt.Fprint(os.Stderr, string(output))
 	return nil
 }
 
+// checkReviewers verifies that every user is a collaborator on the
+// repository and every team exists in the repository's organization, so that
+// mistakes are caught before the pull request is created.
+func checkReviewers(ctx context.Context, names string) error {
+	users, teams := github.SplitReviewers(strings.Split(names, ","))
+	for _, login := range users {
+		ok, err := c.IsCollaborator(ctx, user, repo, login)
+		if err != nil {
+			return fmt.Errorf("error checking reviewer %s: %w", login, err)
+		}
+		if !ok {
+			return fmt.Errorf("%s is not a collaborator on %s/%s", login, user, repo)
+		}
+	}
+	for _, team := range teams {
+		org, slug := github.SplitTeam(team)
+		if !strings.EqualFold(org, user) {
+			return fmt.Errorf("team %s is not in %s; only teams in the repository's organization can review", team, user)
+		}
+		if _, err := c.GetTeam(ctx, user, slug); err != nil {
+			return fmt.Errorf("error checking team %s/%s: %w", user, slug, err)
+		}
+	}
+	return nil
+}
+
+// triage holds the labels, assignees and milestone to set on the pull
+// request, checked against the repository by resolveTriage.
+type triage struct {
+	labels    []string
+	assignees []string
+	milestone int
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// resolveTriage checks -l, -a and -m before the pull request is created or
+// changed, so that unknown names don't leave it half done.
+func resolveTriage(ctx context.Context) (*triage, error) {
+	t := &triage{}
+	if names := splitList(*labels); len(names) > 0 {
+		resolved, err := c.ResolveLabels(ctx, user, repo, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, label := range resolved {
+			t.labels = append(t.labels, label.Name)
+		}
+	}
+	for _, login := range splitList(*assignees) {
+		login = strings.TrimPrefix(login, "@")
+		ok, err := c.IsAssignable(ctx, user, repo, login)
+		if err != nil {
+			return nil, fmt.Errorf("error checking assignee %s: %w", login, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s cannot be assigned in %s/%s", login, user, repo)
+		}
+		t.assignees = append(t.assignees, login)
+	}
+	if *milestone != "" {
+		m, err := c.FindMilestone(ctx, user, repo, *milestone)
+		if err != nil {
+			return nil, err
+		}
+		t.milestone = m.Number
+	}
+	return t, nil
+}
+
+func (t *triage) empty() bool {
+	return len(t.labels) == 0 && len(t.assignees) == 0 && t.milestone == 0
+}
+
+// apply adds the labels and assignees to a pull request and sets its
+// milestone.
+func (t *triage) apply(ctx context.Context, pull int) error {
+	if len(t.labels) > 0 {
+		if _, err := c.AddLabels(ctx, user, repo, pull, t.labels); err != nil {
+			return fmt.Errorf("error adding labels: %w", err)
+		}
+	}
+	if len(t.assignees) > 0 {
+		if _, err := c.AddAssignees(ctx, user, repo, pull, t.assignees); err != nil {
+			return fmt.Errorf("error adding assignees: %w", err)
+		}
+	}
+	if t.milestone != 0 {
+		if err := c.SetMilestone(ctx, user, repo, pull, t.milestone); err != nil {
+			return fmt.Errorf("error setting milestone: %w", err)
+		}
+	}
+	return nil
+}
+
+func requestReviewers(ctx context.Context, pull int, names string) error {
+	users, teams := github.SplitReviewers(strings.Split(names, ","))
+	if _, err := c.RequestReviewers(ctx, user, repo, pull, users, teams); err != nil {
+		return fmt.Errorf("error requesting reviewers: %w", err)
+	}
+	return nil
+}
+
+func removeRequestedReviewers(ctx context.Context, pull int, names string) error {
+	users, teams := github.SplitReviewers(strings.Split(names, ","))
+	if _, err := c.RemoveRequestedReviewers(ctx, user, repo, pull, users, teams); err != nil {
+		return fmt.Errorf("error removing reviewers: %w", err)
+	}
+	return nil
+}
+
+// findPullRequest returns the pull request given by -n, or else the open
+// pull request for branch.
+func findPullRequest(ctx context.Context, branch string) (github.PullRequest, error) {
+	if *number > 0 {
+		return c.GetPullRequest(ctx, user, repo, *number)
+	}
+	head := headOwner + ":" + branch
+	pulls, err := c.GetOpenPullRequests(ctx, user, repo, head, nil)
+	if err != nil {
+		return github.PullRequest{}, err
+	}
+	switch len(pulls) {
+	case 0:
+		return github.PullRequest{}, fmt.Errorf("no open pull request for %s", head)
+	case 1:
+		return pulls[0], nil
+	}
+	return github.PullRequest{}, fmt.Errorf("%d open pull requests for %s; choose one with -n", len(pulls), head)
+}
+
+// changePullRequest applies -update, -title, -body, -close, -reopen, -r, -R,
+// -l, -a and -m to an existing pull request. -update only opens the editor if neither
+// -title nor -body is given.
+func changePullRequest(ctx context.Context, t *triage) error {
+	var branch string
+	if *number <= 0 {
+		if *reopenPull {
+			return errors.New("-reopen requires -n")
+		}
+		var err error
+		if branch, err = getBranch(); err != nil {
+			return err
+		}
+	}
+	pull, err := findPullRequest(ctx, branch)
+	if err != nil {
+		return err
+	}
+	var change github.PullRequestUpdate
+	body, hasBody, err := getBody()
+	if err != nil {
+		return err
+	}
+	if *titleText != "" {
+		change.Title = titleText
+	}
+	if hasBody {
+		change.Body = &body
+	}
+	if *update && change.Title == nil && change.Body == nil {
+		msg := pull.Title
+		if pull.Body != "" {
+			msg += "\n\n" + pull.Body
+		}
+		// Existing descriptions may contain Markdown headings, so
+		// don't strip comments here.
+		title, body, err := github.EditMessage(msg, "")
+		if err != nil {
+			return err
+		}
+		change.Title, change.Body = &title, &body
+	}
+	if *baseBranch != "" {
+		change.Base = baseBranch
+	}
+	if *closePull || *reopenPull {
+		state := "closed"
+		if *reopenPull {
+			state = "open"
+		}
+		change.State = &state
+	}
+	if change != (github.PullRequestUpdate{}) {
+		if pull, err = c.UpdatePullRequest(ctx, user, repo, pull.Number, &change); err != nil {
+			return fmt.Errorf("error updating PR: %w", err)
+		}
+	}
+	if *ready {
+		if !pull.Draft {
+			return fmt.Errorf("#%d is not a draft", pull.Number)
+		}
+		if err := c.MarkPullRequestReadyForReview(ctx, pull.NodeId); err != nil {
+			return fmt.Errorf("error marking PR ready for review: %w", err)
+		}
+	}
+	if *reviewers != "" {
+		if err := requestReviewers(ctx, pull.Number, *reviewers); err != nil {
+			return err
+		}
+	}
+	if *removeReviewers != "" {
+		if err := removeRequestedReviewers(ctx, pull.Number, *removeReviewers); err != nil {
+			return err
+		}
+	}
+	if !t.empty() {
+		if pull, err = applyTriage(ctx, t, pull); err != nil {
+			return err
+		}
+	}
+	return printPullRequest(pull)
+}
+
+// applyTriage applies t to pull, and returns the updated pull request if it
+// is to be printed as JSON.
+func applyTriage(ctx context.Context, t *triage, pull github.PullRequest) (github.PullRequest, error) {
+	if err := t.apply(ctx, pull.Number); err != nil {
+		return pull, err
+	}
+	if !*jsonOutput {
+		return pull, nil
+	}
+	return c.GetPullRequest(ctx, user, repo, pull.Number)
+}
+
+// createPullRequest opens a pull request from headRef into base, taking its
+// title and description from -title, -body, -fill or the editor.
+func createPullRequest(ctx context.Context, commits, branch, headRef, base string) (github.PullRequest, error) {
+	var template, title, body string
+	givenBody, hasBody, err := getBody()
+	if err != nil {
+		return github.PullRequest{}, err
+	}
+
+	switch {
+	case *fill:
+		title, body, err = github.SplitMessage(commits)
+	case *titleText != "":
+		title = *titleText
+	default:
+		template, err = getTemplate(*templateName)
+		if err != nil {
+			return github.PullRequest{}, err
+		}
+		if hasBody {
+			template = givenBody
+		}
+		defaultMsg, commentChar := buildDefaultMessage(commits, template, branch)
+		title, body, err = github.EditMessage(defaultMsg, commentChar)
+		hasBody = false
+	}
+	if err != nil {
+		return github.PullRequest{}, err
+	}
+	if *titleText != "" {
+		title = *titleText
+	}
+	if hasBody {
+		body = givenBody
+	}
+
+	return c.CreatePullRequest(ctx, user, repo, title, body, headRef, base, *draft)
+}
+
 func showError(err error) {
 	fmt.Printf("Error: %s\n", err)
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) && apiErr.DocumentationURL != "" {
+		fmt.Printf("See %s\n", apiErr.DocumentationURL)
+	}
 	os.Exit(1)
 }
 
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [-d] [-p] [-i issue] [-r reviewers]\n\n", os.Args[0])
-		fmt.Print("The pull request will be\n  FROM the remote branch with the same name " +
-			"as your local branch\n  TO master\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [-d] [-p] [-draft] [-base branch] [-remote remote] [-head-remote remote] [-i issue] [-r reviewers]\n"+
+			"          [-l labels] [-a assignees] [-m milestone]\n"+
+			"          [-title title] [-body body | -body-file file] [-fill] [-json]\n"+
+			"       %s [-d] [-n number] [-update] [-title title] [-body body | -body-file file] [-ready]\n"+
+			"          [-close | -reopen] [-r reviewers] [-R reviewers] [-l labels] [-a assignees]\n"+
+			"          [-m milestone] [-json]\n"+
+			"       %s status [-d] [-n number] [-wait]\n\n",
+			os.Args[0], os.Args[0], os.Args[0])
+		fmt.Print("The pull request will be\n  FROM the branch with the same name " +
+			"as your local branch on -head-remote\n  TO -base in the repository of -remote\n\n")
+		fmt.Print("With -n, -update, -ready, -close, -reopen or -R, the existing pull request " +
+			"given by -n\nor opened from the current branch is changed instead.\n\n")
+		fmt.Print("status shows the commit statuses and check runs of the pull request. With -wait\n" +
+			"it waits for them to finish, and it exits non-zero if any failed.\n\n")
+		fmt.Print("-title or -fill skips the editor, so that pull requests can be opened from\n" +
+			"scripts; -fill uses the commit messages as they are.\n\n")
 		fmt.Print("Options:\n")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
+	statusMode := flag.Arg(0) == "status"
+	if statusMode {
+		flag.CommandLine.Parse(flag.Args()[1:])
+	}
 
 	var err error
 
+	// Cancel in-flight requests on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Get repo.
+	remote, err := github.GetRemoteByName(*targetRemote)
+	if err != nil {
+		showError(err)
+	}
+	user, repo = remote.User, remote.Repo
+
+	// Get the repo the branch is pushed to, which differs from the target
+	// repo when working from a fork.
+	if *headRemote == "" {
+		*headRemote = *targetRemote
+	}
+	head, err := github.GetRemoteByName(*headRemote)
+	if err != nil {
+		showError(err)
+	}
+	headOwner = head.User
+
 	// Create API client
-	c, err := github.ApiClientFromHubCredentials()
+	c, err = github.ApiClientForHost(remote.Host)
 	if err != nil {
 		showError(err)
 	}
 	c.Debug = *debug
 
-	// Determine branch.
-	branch, err := getBranch()
+	if statusMode {
+		if err := showStatus(ctx, *wait); err != nil {
+			showError(err)
+		}
+		return
+	}
+
+	if *reviewers != "" {
+		if err := checkReviewers(ctx, *reviewers); err != nil {
+			showError(err)
+		}
+	}
+	t, err := resolveTriage(ctx)
 	if err != nil {
 		showError(err)
 	}
 
-	// Get repo.
-	user, repo, err := github.GetUserAndRepo()
+	if *number > 0 || *update || *ready || *closePull || *reopenPull || *removeReviewers != "" {
+		if *closePull && *reopenPull {
+			showError(errors.New("-close and -reopen cannot be used together"))
+		}
+		if err := changePullRequest(ctx, t); err != nil {
+			showError(err)
+		}
+		return
+	}
+
+	// Determine branch.
+	branch, err := getBranch()
 	if err != nil {
 		showError(err)
 	}
 
-	revs, err := getRevList(branch)
+	// Determine base branch.
+	base := *baseBranch
+	if base == "" {
+		r, err := c.GetRepository(ctx, user, repo)
+		if err != nil {
+			showError(fmt.Errorf("error finding default branch: %w", err))
+		}
+		base = r.DefaultBranch
+	}
+	upstream := *targetRemote + "/" + base
+
+	revs, err := getRevList(upstream, branch)
 	if err != nil {
 		showError(err)
 	}
 	if revs == nil {
 		showError(
 			errors.New(
-				fmt.Sprintf("No commits between origin/master and %s. Did you forget to commit?",
-					branch)))
+				fmt.Sprintf("No commits between %s and %s. Did you forget to commit?",
+					upstream, branch)))
 	}
 
 	if *pushFirst {
-		// Push to origin.
-		err = push(branch)
+		// Push to the head remote.
+		err = push(*headRemote, branch)
 		if err != nil {
 			showError(err)
 		}
 	}
 
+	// Pull requests from a fork name the head as owner:branch.
+	headRef := branch
+	if !strings.EqualFold(headOwner, user) {
+		headRef = headOwner + ":" + branch
+	}
+
 	var pull github.PullRequest
 	if *issue >= 0 {
-		pull = c.CreatePullRequestFromIssue(user, repo, *issue, branch, "master")
+		pull, err = c.CreatePullRequestFromIssue(ctx, user, repo, *issue, headRef, base, *draft)
 	} else {
-		defaultMsg, err := getCommitMessage(branch)
-		if err != nil {
-			showError(err)
-		}
-
-		title, body, err := getCommitMessageFromUser(defaultMsg)
+		var commits string
+		commits, err = getCommitMessage(upstream, branch, !*fill)
 		if err != nil {
 			showError(err)
 		}
-
-		pull = c.CreatePullRequest(user, repo, title, body, branch, "master")
+		pull, err = createPullRequest(ctx, commits, branch, headRef, base)
 	}
-
-	if pull.Errors != nil {
-		showError(errors.New(fmt.Sprintf("error creating PR: %s", pull.Errors)))
+	if err != nil {
+		showError(fmt.Errorf("error creating PR: %w", err))
 	}
 
 	if pull.Number == 0 {
 		showError(errors.New("Unknown error creating pull request"))
 	}
 
-	fmt.Printf("%s\n", pull.IssueUrl)
-
 	if *reviewers != "" {
-		for _, reviewer := range strings.Split(*reviewers, ",") {
-			comment := c.CommentOnPullRequest(user, repo, pull.Number,
-				fmt.Sprintf("@%s Please review at your leisure", reviewer))
-			if comment.Errors != nil {
-				showError(errors.New(fmt.Sprintf("error adding comment: %s", comment.Errors)))
-			}
+		if err := requestReviewers(ctx, pull.Number, *reviewers); err != nil {
+			showError(err)
 		}
 	}
+	if !t.empty() {
+		if pull, err = applyTriage(ctx, t, pull); err != nil {
+			showError(err)
+		}
+	}
+
+	if err := printPullRequest(pull); err != nil {
+		showError(err)
+	}
 }
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreatePullRequestError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message":"Validation Failed","errors":[{"message":"A pull request already exists for o:topic."}]}`))
+	}))
+	defer srv.Close()
+	c = &github.ApiClient{BaseURL: srv.URL + "/", Retry: &github.RetryPolicy{}}
+	user, repo = "o", "r"
+	*titleText = "Add topic"
+	defer func() { *titleText = "" }()
+
+	_, err := createPullRequest(context.Background(), "Add topic", "topic", "topic", "main")
+	var apiErr *github.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("createPullRequest: got %v, want the 422 from GitHub", err)
+	}
+	if apiErr.Errors[0].Message != "A pull request already exists for o:topic." {
+		t.Errorf("error = %v", apiErr)
+	}
+}
+
+func TestCheckReviewersOtherOrg(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+	c = &github.ApiClient{BaseURL: srv.URL + "/"}
+	user, repo = "o", "r"
+
+	err := checkReviewers(context.Background(), "other/reviewers")
+	if err == nil || !strings.Contains(err.Error(), "other/reviewers is not in o") {
+		t.Errorf("checkReviewers = %v, want an error about the org", err)
+	}
+}
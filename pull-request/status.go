@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// pollInterval is how often -wait checks whether CI has finished.
+const pollInterval = 15 * time.Second
+
+// noChecksTimeout is how long -wait waits for the first check to appear,
+// since CI may not have seen a freshly pushed commit yet.
+const noChecksTimeout = 2 * time.Minute
+
+// check is a commit status or check run, reduced to what status shows.
+type check struct {
+	name, state, url string
+	running          bool
+}
+
+func (c check) pending() bool {
+	return c.running
+}
+
+func (c check) failed() bool {
+	switch c.state {
+	case "success", "neutral", "skipped":
+		return false
+	}
+	return !c.pending()
+}
+
+// getChecks returns the latest status for each context and every check run
+// on sha.
+func getChecks(ctx context.Context, sha string) ([]check, error) {
+	status, err := c.GetCombinedStatus(ctx, user, repo, sha)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching statuses: %w", err)
+	}
+	var checks []check
+	for _, s := range status.Statuses {
+		checks = append(checks, check{s.Context, s.State, s.TargetUrl, s.State == "pending"})
+	}
+	runs, err := c.GetCheckRuns(ctx, user, repo, sha, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching check runs: %w", err)
+	}
+	for _, run := range runs {
+		// Runs that haven't completed may be queued, in_progress,
+		// waiting, requested or pending, and have no conclusion yet.
+		state, running := run.Status, run.Status != "completed"
+		if !running {
+			state = run.Conclusion
+		}
+		url := run.DetailsUrl
+		if url == "" {
+			url = run.HtmlUrl
+		}
+		checks = append(checks, check{run.Name, state, url, running})
+	}
+	return checks, nil
+}
+
+// showStatus prints the CI state of the pull request given by -n or opened
+// from the current branch. With wait, it polls until every check has
+// finished, first waiting up to noChecksTimeout for any to appear. It returns
+// an error if any check failed.
+func showStatus(ctx context.Context, wait bool) error {
+	var branch string
+	if *number <= 0 {
+		var err error
+		if branch, err = getBranch(); err != nil {
+			return err
+		}
+	}
+	pull, err := findPullRequest(ctx, branch)
+	if err != nil {
+		return err
+	}
+	var checks []check
+	start := time.Now()
+	for {
+		if checks, err = getChecks(ctx, pull.Head.SHA); err != nil {
+			return err
+		}
+		pending := 0
+		for _, check := range checks {
+			if check.pending() {
+				pending++
+			}
+		}
+		if !wait {
+			break
+		}
+		if len(checks) == 0 {
+			if time.Since(start) >= noChecksTimeout {
+				break
+			}
+			fmt.Fprintf(os.Stderr, "Waiting for checks to start on #%d...\n", pull.Number)
+		} else if pending == 0 {
+			break
+		} else {
+			fmt.Fprintf(os.Stderr, "Waiting for %d of %d checks on #%d...\n", pending, len(checks), pull.Number)
+		}
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	fmt.Printf("#%d %s (%.7s)\n", pull.Number, pull.Title, pull.Head.SHA)
+	if len(checks) == 0 {
+		fmt.Println("No checks")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	failed := 0
+	for _, check := range checks {
+		if check.failed() {
+			failed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", check.state, check.name, check.url)
+	}
+	w.Flush()
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
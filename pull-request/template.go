@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// templatePaths are where GitHub looks for a default pull request template,
+// relative to the top of the repository.
+var templatePaths = []string{
+	".github/PULL_REQUEST_TEMPLATE.md",
+	".github/pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+}
+
+// issueBranchRE matches a branch name that starts with an issue number, such
+// as 1234-fix-foo or user/1234_fix_foo.
+var issueBranchRE = regexp.MustCompile(`(?:^|/)([0-9]+)(?:[-_]|$)`)
+
+func getTopLevel() (string, error) {
+	data, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", errors.New("'git rev-parse --show-toplevel' failed")
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// getTemplate returns the named template from .github/PULL_REQUEST_TEMPLATE/,
+// or the repository's default template if name is empty. It returns "" if
+// there is no default template.
+func getTemplate(name string) (string, error) {
+	top, err := getTopLevel()
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		dir := filepath.Join(top, ".github", "PULL_REQUEST_TEMPLATE")
+		for _, fname := range []string{name, name + ".md"} {
+			if data, err := ioutil.ReadFile(filepath.Join(dir, fname)); err == nil {
+				return string(data), nil
+			}
+		}
+		return "", fmt.Errorf("No template %s in %s", name, dir)
+	}
+	for _, path := range templatePaths {
+		if data, err := ioutil.ReadFile(filepath.Join(top, path)); err == nil {
+			return string(data), nil
+		}
+	}
+	return "", nil
+}
+
+// commentChars are tried in order when the message contains lines starting
+// with the configured comment character, as git does for
+// core.commentChar=auto.
+const commentChars = "#;@!$%^&|:"
+
+// pickCommentChar returns git's comment character if no line of msg starts
+// with it, so that Markdown headings in templates survive editing, and
+// otherwise the first of commentChars that no line starts with.
+func pickCommentChar(msg string) string {
+	used := make(map[string]bool)
+	for _, line := range strings.Split(msg, "\n") {
+		if line != "" {
+			used[line[:1]] = true
+		}
+	}
+	preferred := github.CommentChar()
+	if !used[preferred] {
+		return preferred
+	}
+	for _, char := range strings.Split(commentChars, "") {
+		if !used[char] {
+			return char
+		}
+	}
+	return preferred
+}
+
+// buildDefaultMessage combines the commit messages, the pull request
+// template and a link to the issue named by the branch into the initial
+// contents of the editor, and returns it with the comment character to
+// strip the instructions with.
+func buildDefaultMessage(commits, template, branch string) (msg, commentChar string) {
+	msg = commits
+	if template = strings.TrimSpace(template); template != "" {
+		msg += "\n\n" + template
+	}
+	if matches := issueBranchRE.FindStringSubmatch(branch); matches != nil && !strings.Contains(msg, "#"+matches[1]) {
+		msg += "\n\nFixes #" + matches[1]
+	}
+	commentChar = pickCommentChar(msg)
+	return msg + fmt.Sprintf("\n\n"+
+		"%[1]s Please enter the pull request title on the first line and its\n"+
+		"%[1]s description below. Lines starting with '%[1]s' will be ignored.\n", commentChar), commentChar
+}
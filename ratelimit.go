@@ -0,0 +1,204 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is the API quota reported in the X-RateLimit-* headers of the most
+// recent response that counted against it.
+type Rate struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimit returns the core quota, which covers most REST requests. It is
+// the zero Rate until a request has been made.
+func (c *ApiClient) RateLimit() Rate {
+	return c.RateLimitFor("core")
+}
+
+// RateLimitFor returns the quota for resource, as named by the
+// X-RateLimit-Resource header: "core", "search", "graphql" and so on.
+func (c *ApiClient) RateLimitFor(resource string) Rate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rates[resource]
+}
+
+func (c *ApiClient) updateRate(header http.Header) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, _ := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	reset, _ := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	resource := header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+	c.mu.Lock()
+	if c.rates == nil {
+		c.rates = make(map[string]Rate)
+	}
+	c.rates[resource] = Rate{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+	c.mu.Unlock()
+}
+
+// rateResource returns the quota that a request to url counts against.
+func (c *ApiClient) rateResource(url string) string {
+	if i := strings.Index(url, "?"); i != -1 {
+		url = url[:i]
+	}
+	switch {
+	case strings.HasSuffix(url, "/graphql"):
+		return "graphql"
+	case strings.HasPrefix(url, c.url("search/")):
+		return "search"
+	}
+	return "core"
+}
+
+// waitForQuota sleeps until the quota for resource resets if the last
+// response reported that it was exhausted.
+func (c *ApiClient) waitForQuota(ctx context.Context, policy *RetryPolicy, resource string) error {
+	rate := c.RateLimitFor(resource)
+	if rate.Limit == 0 || rate.Remaining > 0 {
+		return nil
+	}
+	wait := time.Until(rate.Reset)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > policy.MaxWait {
+		return fmt.Errorf("rate limit exhausted until %s", rate.Reset.Format(time.Kitchen))
+	}
+	if c.Debug {
+		fmt.Print("DEBUG: RATE LIMIT EXHAUSTED, WAITING ", wait, "\n")
+	}
+	return sleep(ctx, wait)
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RetryPolicy controls how ApiClient retries failed requests.
+//
+// Requests rejected by a primary or secondary rate limit were never
+// processed, so they are retried regardless of method. Server errors and
+// connection failures are only retried for GET requests unless
+// RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// MinBackoff and MaxBackoff bound the exponential backoff between
+	// attempts. A random jitter of up to half the backoff is subtracted.
+	MinBackoff, MaxBackoff time.Duration
+	// MaxWait is the longest the client will sleep waiting for a rate
+	// limit to reset before giving up with an error.
+	MaxWait time.Duration
+	// RetryNonIdempotent allows retrying POST requests after server
+	// errors and connection failures, which may duplicate their effects.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is used by clients whose Retry field is nil.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 1 * time.Second,
+	MaxBackoff: 30 * time.Second,
+	MaxWait:    15 * time.Minute,
+}
+
+func (c *ApiClient) retryPolicy() *RetryPolicy {
+	if c.Retry != nil {
+		return c.Retry
+	}
+	return &DefaultRetryPolicy
+}
+
+// retryDelay reports whether a failed attempt should be retried and how long
+// to wait first.
+func (p *RetryPolicy) retryDelay(method string, attempt int, header http.Header, transient bool, err error) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && isRateLimited(apiErr, header) {
+		if wait, ok := rateLimitDelay(header); ok {
+			return wait, wait <= p.MaxWait
+		}
+		return p.backoff(attempt), true
+	}
+	if method != "GET" && !p.RetryNonIdempotent {
+		return 0, false
+	}
+	if transient {
+		return p.backoff(attempt), true
+	}
+	if apiErr != nil && apiErr.StatusCode >= 500 {
+		return p.backoff(attempt), true
+	}
+	return 0, false
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff << uint(attempt)
+	if d > p.MaxBackoff || d <= 0 {
+		d = p.MaxBackoff
+	}
+	if half := int64(d / 2); half > 0 {
+		d -= time.Duration(rand.Int63n(half))
+	}
+	return d
+}
+
+// isRateLimited reports whether err is GitHub rejecting a request because
+// of its primary or secondary rate limits.
+func isRateLimited(err *APIError, header http.Header) bool {
+	switch err.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		if header.Get("Retry-After") != "" || header.Get("X-RateLimit-Remaining") == "0" {
+			return true
+		}
+		msg := strings.ToLower(err.Message)
+		return strings.Contains(msg, "rate limit") || strings.Contains(msg, "abuse")
+	}
+	return false
+}
+
+// rateLimitDelay returns how long GitHub asked us to wait, from either
+// Retry-After or an exhausted X-RateLimit-Reset.
+func rateLimitDelay(header http.Header) (time.Duration, bool) {
+	if secs, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			wait := time.Until(time.Unix(reset, 0))
+			if wait < 0 {
+				wait = 0
+			}
+			return wait, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,171 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy retries quickly so that tests don't sleep.
+var testRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: time.Millisecond,
+	MaxBackoff: 5 * time.Millisecond,
+	MaxWait:    time.Second,
+}
+
+// newRetryServer returns a client for a server that calls fail for the
+// first failures requests and then responds with {}. It also returns the
+// number of requests the server has received.
+func newRetryServer(t *testing.T, failures int32, fail func(w http.ResponseWriter)) (*ApiClient, *int32) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			fail(w)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	policy := testRetryPolicy
+	return &ApiClient{BaseURL: srv.URL + "/", Retry: &policy}, &requests
+}
+
+func TestRetryTooManyRequests(t *testing.T) {
+	c, requests := newRetryServer(t, 1, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message":"slow down"}`))
+	})
+	if err := c.get(context.Background(), c.url("x"), nil); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if *requests != 2 {
+		t.Errorf("got %d requests, want 2", *requests)
+	}
+}
+
+func TestRetrySecondaryRateLimit(t *testing.T) {
+	c, requests := newRetryServer(t, 1, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message":"You have exceeded a secondary rate limit."}`))
+	})
+	// Rate limited requests were never processed, so POST is retried too.
+	if err := c.post(context.Background(), c.url("x"), struct{}{}, nil); err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if *requests != 2 {
+		t.Errorf("got %d requests, want 2", *requests)
+	}
+}
+
+func badGateway(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+func TestRetryServerErrorGet(t *testing.T) {
+	c, requests := newRetryServer(t, 1, badGateway)
+	if err := c.get(context.Background(), c.url("x"), nil); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if *requests != 2 {
+		t.Errorf("got %d requests, want 2", *requests)
+	}
+}
+
+func TestNoRetryServerErrorPost(t *testing.T) {
+	c, requests := newRetryServer(t, 1, badGateway)
+	err := c.post(context.Background(), c.url("x"), struct{}{}, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("post: got %v, want a 502 APIError", err)
+	}
+	if *requests != 1 {
+		t.Errorf("got %d requests, want 1", *requests)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	c, requests := newRetryServer(t, 100, badGateway)
+	err := c.get(context.Background(), c.url("x"), nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Fatalf("get: got %v, want a 502 APIError", err)
+	}
+	if want := int32(testRetryPolicy.MaxRetries + 1); *requests != want {
+		t.Errorf("got %d requests, want %d", *requests, want)
+	}
+}
+
+func TestWaitForQuotaBeyondMaxWait(t *testing.T) {
+	c := &ApiClient{}
+	reset := time.Now().Add(time.Hour)
+	c.updateRate(http.Header{
+		"X-Ratelimit-Limit":     {"5000"},
+		"X-Ratelimit-Remaining": {"0"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(reset.Unix(), 10)},
+	})
+	start := time.Now()
+	if err := c.waitForQuota(context.Background(), &testRetryPolicy, "core"); err == nil {
+		t.Fatal("waitForQuota: got nil, want an error")
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("waitForQuota slept for %s before giving up", elapsed)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := &ApiClient{BaseURL: srv.URL + "/"}
+	if rate := c.RateLimit(); rate != (Rate{}) {
+		t.Errorf("RateLimit before any request = %+v, want zero", rate)
+	}
+	if err := c.get(context.Background(), c.url("x"), nil); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	want := Rate{Limit: 5000, Remaining: 4999, Reset: time.Unix(1700000000, 0)}
+	if rate := c.RateLimit(); rate != want {
+		t.Errorf("RateLimit = %+v, want %+v", rate, want)
+	}
+}
+
+func TestRateLimitPerResource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.Header().Set("X-RateLimit-Resource", "search")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	policy := testRetryPolicy
+	c := &ApiClient{BaseURL: srv.URL + "/", Retry: &policy}
+	ctx := context.Background()
+	if err := c.get(ctx, c.url("search/issues?q=x"), nil); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if rate := c.RateLimitFor("search"); rate.Limit != 30 || rate.Remaining != 0 {
+		t.Errorf("RateLimitFor(search) = %+v", rate)
+	}
+	if rate := c.RateLimit(); rate != (Rate{}) {
+		t.Errorf("RateLimit = %+v, want the core quota untouched", rate)
+	}
+	// The exhausted search quota doesn't hold up other requests.
+	if err := c.get(ctx, c.url("repos/o/r"), nil); err != nil {
+		t.Errorf("core request: %v", err)
+	}
+	if err := c.get(ctx, c.url("search/issues?q=y"), nil); err == nil {
+		t.Error("search request: got nil, want a rate limit error")
+	}
+}
@@ -0,0 +1,16 @@
+package github
+
+import (
+	"context"
+)
+
+// GetRepository returns a repository, including its default branch.
+func (c *ApiClient) GetRepository(ctx context.Context, user, project string) (Repo, error) {
+	url := c.url(
+		"repos/%s/%s",
+		user,
+		project)
+	var r Repo
+	err := c.get(ctx, url, &r)
+	return r, err
+}
@@ -0,0 +1,224 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// DefaultAPIVersion is the REST API version requested by clients whose
+// APIVersion is empty.
+const DefaultAPIVersion = "2022-11-28"
+
+// DefaultMediaType is the Accept header sent unless a request overrides it.
+const DefaultMediaType = "application/vnd.github+json"
+
+// Media types for fetching pull requests, commits and comparisons as a
+// unified diff or as a series of patches in git format-patch form.
+const (
+	DiffMediaType  = "application/vnd.github.diff"
+	PatchMediaType = "application/vnd.github.patch"
+)
+
+// RawMediaType fetches the contents of a file as is, rather than
+// base64-encoded in JSON.
+const RawMediaType = "application/vnd.github.raw"
+
+// Request describes a call to the API.
+type Request struct {
+	Method string
+	// URL is either absolute or relative to the client's BaseURL.
+	URL   string
+	Query neturl.Values
+	// Header is sent in addition to the default Accept, API version and
+	// Authorization headers, replacing any of them it sets. Set Accept to
+	// request a preview or raw media type.
+	Header http.Header
+	// Body, if non-nil, is sent as JSON.
+	Body interface{}
+}
+
+// Do sends req and decodes the JSON response into v, which may be nil.
+func (c *ApiClient) Do(ctx context.Context, req *Request, v interface{}) (http.Header, error) {
+	body, header, err := c.DoRaw(ctx, req)
+	if err != nil {
+		return header, err
+	}
+	if v == nil {
+		return header, nil
+	}
+	return header, decode(req.URL, body, v)
+}
+
+// DoRaw sends req and returns the undecoded response body.
+func (c *ApiClient) DoRaw(ctx context.Context, req *Request) ([]byte, http.Header, error) {
+	return c.load(ctx, req)
+}
+
+func (c *ApiClient) load(ctx context.Context, req *Request) ([]byte, http.Header, error) {
+	url, err := c.resolve(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	var data []byte
+	if req.Body != nil {
+		if data, err = json.Marshal(req.Body); err != nil {
+			return nil, nil, err
+		}
+	}
+	policy := c.retryPolicy()
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForQuota(ctx, policy, c.rateResource(url)); err != nil {
+			return nil, nil, err
+		}
+		body, header, transient, err := c.send(ctx, req, url, data)
+		if err == nil {
+			return body, header, nil
+		}
+		wait, ok := policy.retryDelay(req.Method, attempt, header, transient, err)
+		if !ok {
+			return nil, header, err
+		}
+		if c.Debug {
+			fmt.Print("DEBUG: RETRYING IN ", wait, ": ", err, "\n")
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// resolve returns the absolute URL for req, including its query parameters.
+func (c *ApiClient) resolve(req *Request) (string, error) {
+	url := req.URL
+	if !strings.Contains(url, "://") {
+		url = c.url("%s", strings.TrimPrefix(url, "/"))
+	}
+	if len(req.Query) == 0 {
+		return url, nil
+	}
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for k, vs := range req.Query {
+		q[k] = vs
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// send makes a single attempt at a request. transient reports whether a
+// non-nil err came from the connection rather than from GitHub.
+func (c *ApiClient) send(ctx context.Context, r *Request, url string, data []byte) (body []byte, header http.Header, transient bool, err error) {
+	method := r.Method
+	if method == "" {
+		method = "GET"
+	}
+	if c.Debug {
+		c.debugOnce.Do(c.debugCredentials)
+		fmt.Print("DEBUG: REQUEST: ", method, " ", url, "\n", string(data), "\n")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	req.ContentLength = int64(len(data))
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", DefaultMediaType)
+	version := c.APIVersion
+	if version == "" {
+		version = DefaultAPIVersion
+	}
+	req.Header.Set("X-GitHub-Api-Version", version)
+	token := c.OAuthToken
+	if c.TokenSource != nil {
+		if token, err = c.TokenSource.Token(ctx); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	for k, vs := range r.Header {
+		req.Header[http.CanonicalHeaderKey(k)] = vs
+	}
+	// Responses differ by media type, so it is part of the cache key.
+	cacheKey := req.Header.Get("Accept") + " " + url
+	var cached *cacheEntry
+	if c.Cache != nil && method == "GET" {
+		cached = c.Cache.get(cacheKey)
+		cached.setConditions(req)
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, nil, true, err
+	}
+	defer resp.Body.Close()
+	c.updateRate(resp.Header)
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.Header, true, err
+	}
+	if c.Debug {
+		fmt.Print("DEBUG: RESPONSE: ", resp.Status, "\n", string(body), "\n")
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		c.Cache.hit()
+		if c.Debug {
+			fmt.Print("DEBUG: CACHE: serving ", len(cached.Body), " cached bytes; ", c.Cache.Stats(), "\n")
+		}
+		return cached.Body, cached.Header, false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, resp.Header, false, newAPIError(resp.StatusCode, body)
+	}
+	if c.Cache != nil && method == "GET" {
+		c.Cache.miss()
+		if err := c.Cache.put(cacheKey, resp.Header, body); err != nil && c.Debug {
+			fmt.Print("DEBUG: CACHE: could not store response: ", err, "\n")
+		}
+	}
+	return body, resp.Header, false, nil
+}
+
+// get fetches url and decodes the JSON response into v.
+func (c *ApiClient) get(ctx context.Context, url string, v interface{}) error {
+	_, err := c.Do(ctx, &Request{Method: "GET", URL: url}, v)
+	return err
+}
+
+// getRaw fetches url as mediaType and returns the undecoded response.
+func (c *ApiClient) getRaw(ctx context.Context, url, mediaType string) ([]byte, error) {
+	header := http.Header{"Accept": {mediaType}}
+	body, _, err := c.DoRaw(ctx, &Request{Method: "GET", URL: url, Header: header})
+	return body, err
+}
+
+// post sends req as JSON to url and decodes the JSON response into v.
+func (c *ApiClient) post(ctx context.Context, url string, req, v interface{}) error {
+	return c.do(ctx, "POST", url, req, v)
+}
+
+// do sends body as JSON to url with the given method and decodes the JSON
+// response into v. Either may be nil.
+func (c *ApiClient) do(ctx context.Context, method, url string, body, v interface{}) error {
+	_, err := c.Do(ctx, &Request{Method: method, URL: url, Body: body}, v)
+	return err
+}
+
+func decode(url string, body []byte, v interface{}) error {
+	if len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("could not decode response from %s: %s", url, err)
+	}
+	return nil
+}
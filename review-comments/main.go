@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"github"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"os/signal"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -14,7 +18,13 @@ import (
 
 var width = flag.Int("W", 120, "max diff output width")
 
-var user = "judicata"
+var debug = flag.Bool("d", false, "show debug output for network requests")
+
+var commitComments = flag.Bool("c", false, "also show comments made on individual commits")
+
+var noCache = flag.Bool("no-cache", false, "don't use or update the on-disk response cache")
+
+var user string
 
 var c *github.ApiClient
 
@@ -23,14 +33,64 @@ func diff(file1, file2 string) string {
 	return string(data)
 }
 
-func fetch(path, sha, output_fname string) {
+func fetch(path, sha, output_fname string) error {
 	data, err := exec.Command("git", "show", fmt.Sprintf("%s:%s", sha, path)).Output()
 	if err != nil {
-		panic(err)
+		return err
 	}
-	if err := ioutil.WriteFile(output_fname, data, 0644); err != nil {
-		panic(err)
+	return ioutil.WriteFile(output_fname, data, 0644)
+}
+
+var hunkRE = regexp.MustCompile(`^@@ -[0-9]+(?:,[0-9]+)? \+([0-9]+)(?:,([0-9]+))? @@`)
+
+// hunkForLine returns the hunk of a unified diff patch that contains line
+// of the new file, or "" if there is none.
+func hunkForLine(patch string, line int) string {
+	var hunk []string
+	found := false
+	for _, l := range strings.Split(patch, "\n") {
+		if matches := hunkRE.FindStringSubmatch(l); matches != nil {
+			if found {
+				break
+			}
+			start, _ := strconv.Atoi(matches[1])
+			count := 1
+			if matches[2] != "" {
+				count, _ = strconv.Atoi(matches[2])
+			}
+			found = line >= start && line < start+count
+			hunk = nil
+		}
+		hunk = append(hunk, l)
 	}
+	if !found {
+		return ""
+	}
+	return strings.Join(hunk, "\n")
+}
+
+// patches holds the patch of each file in the pull request, fetched the
+// first time a comment's commit is not available locally.
+var patches map[string]string
+
+// remoteContext returns the diff context for a comment whose commit is not
+// in the local clone, using the comment's own diff hunk if it has one and
+// otherwise the pull request's patch for the file.
+func remoteContext(ctx context.Context, pull github.PullRequest, comment github.Comment) (string, error) {
+	if comment.DiffHunk != "" {
+		return comment.DiffHunk, nil
+	}
+	if patches == nil {
+		files, err := c.GetPullRequestFiles(ctx, user, pull.Base.Repo.Name, pull.Number, nil)
+		if err != nil {
+			return "", fmt.Errorf("error fetching files of #%d: %w", pull.Number, err)
+		}
+		patches = make(map[string]string)
+		for _, file := range files {
+			patches[file.Filename] = file.Patch
+		}
+	}
+	return hunkForLine(patches[comment.Path], comment.Line), nil
 }
 
 func guessDiffColumn(diffLines []string) int {
@@ -97,13 +157,14 @@ func read(fname string) string {
 	return string(data)
 }
 
-func showComments(pull github.PullRequest, comments github.CommentList) {
+func showComments(ctx context.Context, pull github.PullRequest, comments github.CommentList) error {
 	sort.Sort(comments)
 	head_sha := pull.Head.SHA
 	ci := 0
 	for ci < len(comments) {
 		fmt.Println("\n\n=============================================")
-		path, line, sha := comments[ci].Path, comments[ci].Line, comments[ci].CommitId
+		first := comments[ci]
+		path, line, sha := first.Path, first.Line, first.CommitId
 		for ci < len(comments) && comments[ci].Path == path && comments[ci].Line == line {
 			fmt.Printf("\033[1;30m%s\033[0m: %s\n", comments[ci].User.Login, comments[ci].Body)
 			ci++
@@ -111,8 +172,15 @@ func showComments(pull github.PullRequest, comments github.CommentList) {
 		if line == 0 {
 			continue
 		}
-		fetch(path, sha, "/tmp/before")
-		fetch(path, head_sha, "/tmp/after")
+		if fetch(path, sha, "/tmp/before") != nil || fetch(path, head_sha, "/tmp/after") != nil {
+			// The commits haven't been fetched; show what GitHub has.
+			hunk, err := remoteContext(ctx, pull, first)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s:%d\n%s\n", path, line, hunk)
+			continue
+		}
 		d := diff("/tmp/before", "/tmp/after")
 		before := read("/tmp/before")
 		dl := strings.Split(d, "\n")
@@ -128,50 +196,113 @@ func showComments(pull github.PullRequest, comments github.CommentList) {
 			fmt.Println(dl[i])
 		}
 	}
+	return nil
 }
 
-func gitLog(sha1, sha2 string) []string {
-	data, err := exec.Command("git", "log", "--format=format:%H", fmt.Sprintf("%s..%s", sha1, sha2)).CombinedOutput()
+// gitLog returns the commits in a pull request, from the local repository
+// if it has them and otherwise from GitHub.
+func gitLog(ctx context.Context, pull github.PullRequest) ([]string, error) {
+	data, err := exec.Command("git", "log", "--format=format:%H", fmt.Sprintf("%s..%s", pull.Base.SHA, pull.Head.SHA)).Output()
+	if err == nil {
+		return strings.Split(string(data), "\n"), nil
+	}
+	if c.Debug {
+		fmt.Printf("DEBUG: 'git log' failed, listing commits of #%d from GitHub\n", pull.Number)
+	}
+	commits, err := c.GetPullRequestCommits(ctx, user, pull.Base.Repo.Name, pull.Number, &github.ListOptions{PerPage: 100})
 	if err != nil {
-		fmt.Print(string(data))
-		panic(err)
+		return nil, fmt.Errorf("error listing commits of #%d: %w", pull.Number, err)
 	}
-	return strings.Split(string(data), "\n")
+	var shas []string
+	for _, commit := range commits {
+		shas = append(shas, commit.SHA)
+	}
+	return shas, nil
 }
 
-func getAllComments(pull github.PullRequest) {
-	log := gitLog(pull.Base.SHA, pull.Head.SHA)
-	project := pull.Head.Repo.Name
-	comments := c.GetPullRequestComments(user, project, pull.Number)
-	for _, sha := range log {
-		for _, comment := range c.GetCommitComments(user, project, sha) {
-			comments = append(comments, comment)
+func getAllComments(ctx context.Context, pull github.PullRequest) error {
+	project := pull.Base.Repo.Name
+	comments, err := c.GetPullRequestComments(ctx, user, project, pull.Number, nil)
+	if err != nil {
+		return fmt.Errorf("error fetching comments on #%d: %w", pull.Number, err)
+	}
+	reviewComments, err := c.GetReviewComments(ctx, user, project, pull.Number, nil)
+	if err != nil {
+		return fmt.Errorf("error fetching review comments on #%d: %w", pull.Number, err)
+	}
+	for _, comment := range reviewComments {
+		// Outdated comments no longer have a line in the current diff;
+		// show them where they were originally made.
+		if comment.Line == 0 && comment.OriginalLine != 0 {
+			comment.Line = comment.OriginalLine
+			comment.CommitId = comment.OriginalCommitId
+		}
+		if comment.Side == "LEFT" {
+			comment.CommitId = pull.Base.SHA
+		}
+		comments = append(comments, comment)
+	}
+	if *commitComments {
+		shas, err := gitLog(ctx, pull)
+		if err != nil {
+			return err
+		}
+		for _, sha := range shas {
+			shaComments, err := c.GetCommitComments(ctx, user, project, sha, nil)
+			if err != nil {
+				return fmt.Errorf("error fetching comments on %s: %w", sha, err)
+			}
+			comments = append(comments, shaComments...)
 		}
 	}
-	showComments(pull, comments)
+	return showComments(ctx, pull, comments)
 }
 
 func showError(err error) {
 	fmt.Printf("Error: %s\n", err)
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) && apiErr.DocumentationURL != "" {
+		fmt.Printf("See %s\n", apiErr.DocumentationURL)
+	}
 	os.Exit(1)
 }
 
 func main() {
 	flag.Parse()
+
+	// Cancel in-flight requests on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	pull, err := strconv.ParseInt(flag.Arg(0), 10, 64)
 	if err != nil {
 		showError(err)
 	}
 
-	c, err = github.ApiClientFromHubCredentials()
+	remote, err := github.GetRemote()
 	if err != nil {
 		showError(err)
 	}
+	user = remote.User
+	repo := remote.Repo
 
-	user, repo, err := github.GetUserAndRepo()
+	c, err = github.ApiClientForHost(remote.Host)
 	if err != nil {
 		showError(err)
 	}
+	c.Debug = *debug
+	if !*noCache {
+		c.Cache = github.NewCache("")
+	}
 
-	getAllComments(c.GetPullRequest(user, repo, int(pull)))
+	pr, err := c.GetPullRequest(ctx, user, repo, int(pull))
+	if err != nil {
+		showError(fmt.Errorf("error fetching pull request #%d: %w", pull, err))
+	}
+	if err := getAllComments(ctx, pr); err != nil {
+		showError(err)
+	}
+	if c.Debug && c.Cache != nil {
+		fmt.Printf("DEBUG: CACHE: %s in %s\n", c.Cache.Stats(), c.Cache.Dir)
+	}
 }
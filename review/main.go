@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+)
+
+var debug = flag.Bool("d", false, "show debug output for network requests")
+
+var message = flag.String("m", "", "review body, or reason for dismissing a review")
+
+var comments commentFlags
+
+func init() {
+	flag.Var(&comments, "c", "inline comment as path:line:text or path:start-end:text (may be repeated)")
+}
+
+// commentFlags collects repeated -c flags.
+type commentFlags []github.DraftReviewComment
+
+func (f *commentFlags) String() string {
+	return fmt.Sprint(len(*f), " comments")
+}
+
+func (f *commentFlags) Set(value string) error {
+	pieces := strings.SplitN(value, ":", 3)
+	if len(pieces) != 3 || pieces[0] == "" || pieces[2] == "" {
+		return errors.New("expected path:line:text")
+	}
+	comment := github.DraftReviewComment{Path: pieces[0], Body: pieces[2]}
+	lines := strings.SplitN(pieces[1], "-", 2)
+	var err error
+	if comment.Line, err = strconv.Atoi(lines[len(lines)-1]); err != nil {
+		return errors.New("invalid line number: " + pieces[1])
+	}
+	if len(lines) == 2 {
+		if comment.StartLine, err = strconv.Atoi(lines[0]); err != nil {
+			return errors.New("invalid line number: " + pieces[1])
+		}
+	}
+	*f = append(*f, comment)
+	return nil
+}
+
+var c *github.ApiClient
+
+var user, repo string
+
+func list(ctx context.Context, pull int) error {
+	reviews, err := c.GetReviews(ctx, user, repo, pull, nil)
+	if err != nil {
+		return err
+	}
+	for _, review := range reviews {
+		fmt.Printf("%d\t%s\t%s\t%s\n", review.Id, review.State, review.User.Login, review.Submitted)
+		if review.Body != "" {
+			fmt.Printf("\t%s\n", strings.Replace(review.Body, "\n", "\n\t", -1))
+		}
+	}
+	return nil
+}
+
+func submit(ctx context.Context, pull int, event string) error {
+	// Collect the inline comments in a pending review first, so that they
+	// are all published together with the verdict.
+	review, err := c.CreateReview(ctx, user, repo, pull, &github.ReviewRequest{Comments: comments})
+	if err != nil {
+		return fmt.Errorf("error creating review: %w", err)
+	}
+	submitted, err := c.SubmitReview(ctx, user, repo, pull, review.Id, event, *message)
+	if err != nil {
+		if delErr := c.DeletePendingReview(ctx, user, repo, pull, review.Id); delErr != nil {
+			fmt.Printf("Warning: could not delete pending review %d: %s\n", review.Id, delErr)
+		}
+		return fmt.Errorf("error submitting review: %w", err)
+	}
+	fmt.Printf("%s review %d with %d comments\n", submitted.State, submitted.Id, len(comments))
+	return nil
+}
+
+func dismiss(ctx context.Context, pull int, id int64) error {
+	if *message == "" {
+		return errors.New("-m is required to dismiss a review")
+	}
+	review, err := c.DismissReview(ctx, user, repo, pull, id, *message)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s review %d\n", review.State, review.Id)
+	return nil
+}
+
+func showError(err error) {
+	fmt.Printf("Error: %s\n", err)
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) && apiErr.DocumentationURL != "" {
+		fmt.Printf("See %s\n", apiErr.DocumentationURL)
+	}
+	os.Exit(1)
+}
+
+func usageAndQuit() {
+	flag.Usage()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s <command> [options] <pull>\n", os.Args[0])
+		fmt.Fprint(os.Stderr, `
+Commands:
+  list             list the reviews on a pull request
+  approve          approve a pull request
+  request-changes  request changes on a pull request; requires -m
+  comment          comment on a pull request without a verdict; requires -m
+  dismiss          dismiss a review, given as <pull> <review-id>; -m gives the reason
+
+Options:
+`)
+		flag.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		usageAndQuit()
+	}
+	command := os.Args[1]
+	flag.CommandLine.Parse(os.Args[2:])
+	if flag.NArg() < 1 {
+		usageAndQuit()
+	}
+	pull, err := strconv.Atoi(flag.Arg(0))
+	if err != nil {
+		showError(errors.New("invalid pull request number: " + flag.Arg(0)))
+	}
+
+	// Cancel in-flight requests on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	remote, err := github.GetRemote()
+	if err != nil {
+		showError(err)
+	}
+	user, repo = remote.User, remote.Repo
+
+	c, err = github.ApiClientForHost(remote.Host)
+	if err != nil {
+		showError(err)
+	}
+	c.Debug = *debug
+
+	switch command {
+	case "list":
+		err = list(ctx, pull)
+	case "approve":
+		err = submit(ctx, pull, github.ReviewApprove)
+	case "request-changes":
+		// GitHub rejects these events without a body, even if the review
+		// has inline comments.
+		if *message == "" {
+			err = errors.New("-m is required to request changes")
+			break
+		}
+		err = submit(ctx, pull, github.ReviewRequestChanges)
+	case "comment":
+		if *message == "" {
+			err = errors.New("-m is required to comment")
+			break
+		}
+		err = submit(ctx, pull, github.ReviewComment)
+	case "dismiss":
+		if flag.NArg() < 2 {
+			usageAndQuit()
+		}
+		var id int64
+		if id, err = strconv.ParseInt(flag.Arg(1), 10, 64); err != nil {
+			err = errors.New("invalid review id: " + flag.Arg(1))
+			break
+		}
+		err = dismiss(ctx, pull, id)
+	default:
+		usageAndQuit()
+	}
+	if err != nil {
+		showError(err)
+	}
+}
@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+)
+
+// ReviewCommentRequest is an inline comment on the diff of a pull request.
+// To comment on a range of lines, set StartLine (and StartSide) to the first
+// line and Line to the last.
+type ReviewCommentRequest struct {
+	Body     string `json:"body"`
+	CommitId string `json:"commit_id"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	// Side is "RIGHT" (the default) for lines in the head of the pull
+	// request, or "LEFT" for deleted lines.
+	Side      string `json:"side,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+}
+
+// ListReviewComments calls fn with each page of inline review comments on a
+// pull request.
+func (c *ApiClient) ListReviewComments(ctx context.Context, user, project string, pull int, opts *ListOptions, fn func(CommentList) error) error {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/comments",
+		user,
+		project,
+		pull)
+	return c.eachComment(ctx, url, opts, fn)
+}
+
+// GetReviewComments returns the inline review comments on a pull request
+// from every page.
+func (c *ApiClient) GetReviewComments(ctx context.Context, user, project string, pull int, opts *ListOptions) (CommentList, error) {
+	var comments CommentList
+	err := c.ListReviewComments(ctx, user, project, pull, opts, func(page CommentList) error {
+		comments = append(comments, page...)
+		return nil
+	})
+	return comments, err
+}
+
+// GetReviewComment returns a single review comment.
+func (c *ApiClient) GetReviewComment(ctx context.Context, user, project string, id int64) (Comment, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/comments/%d",
+		user,
+		project,
+		id)
+	var comment Comment
+	err := c.get(ctx, url, &comment)
+	return comment, err
+}
+
+// CreateReviewComment adds an inline comment to the diff of a pull request.
+func (c *ApiClient) CreateReviewComment(ctx context.Context, user, project string, pull int, req *ReviewCommentRequest) (Comment, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/comments",
+		user,
+		project,
+		pull)
+	var comment Comment
+	err := c.post(ctx, url, req, &comment)
+	return comment, err
+}
+
+// ReplyToReviewComment adds a reply to the thread started by the review
+// comment id.
+func (c *ApiClient) ReplyToReviewComment(ctx context.Context, user, project string, pull int, id int64, body string) (Comment, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/comments/%d/replies",
+		user,
+		project,
+		pull,
+		id)
+	var comment Comment
+	err := c.post(ctx, url, &BodyOnlyComment{Body: body}, &comment)
+	return comment, err
+}
+
+// EditReviewComment replaces the body of a review comment.
+func (c *ApiClient) EditReviewComment(ctx context.Context, user, project string, id int64, body string) (Comment, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/comments/%d",
+		user,
+		project,
+		id)
+	var comment Comment
+	err := c.do(ctx, "PATCH", url, &BodyOnlyComment{Body: body}, &comment)
+	return comment, err
+}
+
+// DeleteReviewComment deletes a review comment.
+func (c *ApiClient) DeleteReviewComment(ctx context.Context, user, project string, id int64) error {
+	url := c.url(
+		"repos/%s/%s/pulls/comments/%d",
+		user,
+		project,
+		id)
+	return c.do(ctx, "DELETE", url, nil, nil)
+}
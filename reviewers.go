@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type Team struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type reviewersRequest struct {
+	Reviewers     []string `json:"reviewers,omitempty"`
+	TeamReviewers []string `json:"team_reviewers,omitempty"`
+}
+
+// SplitReviewers separates reviewer names into user logins and teams, which
+// are written as "org/team".
+func SplitReviewers(names []string) (users, teams []string) {
+	for _, name := range names {
+		name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+		if name == "" {
+			continue
+		}
+		if strings.Contains(name, "/") {
+			teams = append(teams, name)
+		} else {
+			users = append(users, name)
+		}
+	}
+	return users, teams
+}
+
+// SplitTeam splits "org/team" into the org and the team's slug. The org is
+// empty if team is just a slug.
+func SplitTeam(team string) (org, slug string) {
+	if i := strings.Index(team, "/"); i != -1 {
+		return team[:i], team[i+1:]
+	}
+	return "", team
+}
+
+// teamSlugs returns the slugs of teams given as "org/team" or as slugs. A
+// pull request can only request reviews from teams in its own organization,
+// so the API takes slugs alone.
+func teamSlugs(teams []string) []string {
+	var slugs []string
+	for _, team := range teams {
+		_, slug := SplitTeam(team)
+		slugs = append(slugs, slug)
+	}
+	return slugs
+}
+
+// RequestReviewers asks users and teams (by slug or "org/team") to review a
+// pull request.
+func (c *ApiClient) RequestReviewers(ctx context.Context, user, project string, pull int, users, teams []string) (PullRequest, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/requested_reviewers",
+		user,
+		project,
+		pull)
+	var pr PullRequest
+	err := c.post(ctx, url, &reviewersRequest{Reviewers: users, TeamReviewers: teamSlugs(teams)}, &pr)
+	return pr, err
+}
+
+// RemoveRequestedReviewers withdraws review requests from users and teams.
+func (c *ApiClient) RemoveRequestedReviewers(ctx context.Context, user, project string, pull int, users, teams []string) (PullRequest, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/requested_reviewers",
+		user,
+		project,
+		pull)
+	var pr PullRequest
+	err := c.do(ctx, "DELETE", url, &reviewersRequest{Reviewers: users, TeamReviewers: teamSlugs(teams)}, &pr)
+	return pr, err
+}
+
+// IsCollaborator reports whether login is a collaborator on a repository.
+func (c *ApiClient) IsCollaborator(ctx context.Context, user, project, login string) (bool, error) {
+	url := c.url(
+		"repos/%s/%s/collaborators/%s",
+		user,
+		project,
+		login)
+	err := c.get(ctx, url, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetTeam returns the team with the given slug in org.
+func (c *ApiClient) GetTeam(ctx context.Context, org, slug string) (Team, error) {
+	url := c.url(
+		"orgs/%s/teams/%s",
+		org,
+		slug)
+	var team Team
+	err := c.get(ctx, url, &team)
+	return team, err
+}
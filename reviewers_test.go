@@ -0,0 +1,19 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitReviewers(t *testing.T) {
+	users, teams := SplitReviewers([]string{"alice", " @bob", "", "org/core", "@org/docs"})
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(users, want) {
+		t.Errorf("users = %q, want %q", users, want)
+	}
+	if want := []string{"org/core", "org/docs"}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("teams = %q, want %q", teams, want)
+	}
+	if want := []string{"core", "docs"}; !reflect.DeepEqual(teamSlugs(teams), want) {
+		t.Errorf("teamSlugs = %q, want %q", teamSlugs(teams), want)
+	}
+}
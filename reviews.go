@@ -0,0 +1,128 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Review events passed to CreateReview and SubmitReview.
+const (
+	ReviewApprove        = "APPROVE"
+	ReviewRequestChanges = "REQUEST_CHANGES"
+	ReviewComment        = "COMMENT"
+)
+
+type Review struct {
+	Id   int64 `json:"id"`
+	User User
+	Body string
+	// State is APPROVED, CHANGES_REQUESTED, COMMENTED, DISMISSED or
+	// PENDING.
+	State     string
+	CommitId  string `json:"commit_id"`
+	Submitted string `json:"submitted_at"`
+}
+
+// ReviewRequest creates a review. If Event is empty the review is left
+// pending until it is submitted with SubmitReview.
+type ReviewRequest struct {
+	CommitId string               `json:"commit_id,omitempty"`
+	Body     string               `json:"body,omitempty"`
+	Event    string               `json:"event,omitempty"`
+	Comments []DraftReviewComment `json:"comments,omitempty"`
+}
+
+// DraftReviewComment is an inline comment submitted as part of a review.
+type DraftReviewComment struct {
+	Path      string `json:"path"`
+	Body      string `json:"body"`
+	Line      int    `json:"line"`
+	Side      string `json:"side,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+}
+
+type submitReviewRequest struct {
+	Body  string `json:"body,omitempty"`
+	Event string `json:"event"`
+}
+
+type dismissReviewRequest struct {
+	Message string `json:"message"`
+}
+
+// ListReviews calls fn with each page of reviews on a pull request.
+func (c *ApiClient) ListReviews(ctx context.Context, user, project string, pull int, opts *ListOptions, fn func([]Review) error) error {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/reviews",
+		user,
+		project,
+		pull)
+	return c.eachPage(ctx, url, opts, func(page []json.RawMessage) error {
+		var reviews []Review
+		if err := decodePage(url, page, &reviews); err != nil {
+			return err
+		}
+		return fn(reviews)
+	})
+}
+
+// GetReviews returns the reviews on a pull request from every page.
+func (c *ApiClient) GetReviews(ctx context.Context, user, project string, pull int, opts *ListOptions) ([]Review, error) {
+	var reviews []Review
+	err := c.ListReviews(ctx, user, project, pull, opts, func(page []Review) error {
+		reviews = append(reviews, page...)
+		return nil
+	})
+	return reviews, err
+}
+
+// CreateReview starts a review on a pull request, submitting it at once if
+// req.Event is set.
+func (c *ApiClient) CreateReview(ctx context.Context, user, project string, pull int, req *ReviewRequest) (Review, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/reviews",
+		user,
+		project,
+		pull)
+	var review Review
+	err := c.post(ctx, url, req, &review)
+	return review, err
+}
+
+// SubmitReview submits a pending review with one of the Review* events.
+func (c *ApiClient) SubmitReview(ctx context.Context, user, project string, pull int, id int64, event, body string) (Review, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/reviews/%d/events",
+		user,
+		project,
+		pull,
+		id)
+	var review Review
+	err := c.post(ctx, url, &submitReviewRequest{Body: body, Event: event}, &review)
+	return review, err
+}
+
+// DeletePendingReview discards a review that has not been submitted.
+func (c *ApiClient) DeletePendingReview(ctx context.Context, user, project string, pull int, id int64) error {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/reviews/%d",
+		user,
+		project,
+		pull,
+		id)
+	return c.do(ctx, "DELETE", url, nil, nil)
+}
+
+// DismissReview dismisses a submitted review, explaining why in message.
+func (c *ApiClient) DismissReview(ctx context.Context, user, project string, pull int, id int64, message string) (Review, error) {
+	url := c.url(
+		"repos/%s/%s/pulls/%d/reviews/%d/dismissals",
+		user,
+		project,
+		pull,
+		id)
+	var review Review
+	err := c.do(ctx, "PUT", url, &dismissReviewRequest{Message: message}, &review)
+	return review, err
+}
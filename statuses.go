@@ -0,0 +1,90 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Status is a commit status reported by an external service.
+type Status struct {
+	Id      int64 `json:"id"`
+	Context string
+	// State is "error", "failure", "pending" or "success".
+	State       string
+	Description string
+	TargetUrl   string `json:"target_url"`
+	Creator     User
+	Created     string `json:"created_at"`
+	Updated     string `json:"updated_at"`
+}
+
+// CombinedStatus is the overall state of a commit's statuses, with the
+// latest status for each context.
+type CombinedStatus struct {
+	State    string
+	SHA      string
+	Statuses []Status
+}
+
+// StatusRequest creates a commit status.
+type StatusRequest struct {
+	// State is "error", "failure", "pending" or "success".
+	State       string `json:"state"`
+	TargetUrl   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Context distinguishes this status from those of other services. If
+	// empty, GitHub uses "default".
+	Context string `json:"context,omitempty"`
+}
+
+// CreateStatus reports a status on the commit sha.
+func (c *ApiClient) CreateStatus(ctx context.Context, user, project, sha string, req *StatusRequest) (Status, error) {
+	url := c.url(
+		"repos/%s/%s/statuses/%s",
+		user,
+		project,
+		sha)
+	var status Status
+	err := c.post(ctx, url, req, &status)
+	return status, err
+}
+
+// GetCombinedStatus returns the latest status for each context on ref.
+func (c *ApiClient) GetCombinedStatus(ctx context.Context, user, project, ref string) (CombinedStatus, error) {
+	url := c.url(
+		"repos/%s/%s/commits/%s/status",
+		user,
+		project,
+		ref)
+	var status CombinedStatus
+	err := c.get(ctx, url, &status)
+	return status, err
+}
+
+// ListStatuses calls fn with each page of statuses on ref, newest first.
+// Unlike GetCombinedStatus, this includes statuses that have since been
+// replaced by newer ones for the same context.
+func (c *ApiClient) ListStatuses(ctx context.Context, user, project, ref string, opts *ListOptions, fn func([]Status) error) error {
+	url := c.url(
+		"repos/%s/%s/commits/%s/statuses",
+		user,
+		project,
+		ref)
+	return c.eachPage(ctx, url, opts, func(page []json.RawMessage) error {
+		var statuses []Status
+		if err := decodePage(url, page, &statuses); err != nil {
+			return err
+		}
+		return fn(statuses)
+	})
+}
+
+// GetStatuses returns the statuses on ref from every page.
+func (c *ApiClient) GetStatuses(ctx context.Context, user, project, ref string, opts *ListOptions) ([]Status, error) {
+	var statuses []Status
+	err := c.ListStatuses(ctx, user, project, ref, opts, func(page []Status) error {
+		statuses = append(statuses, page...)
+		return nil
+	})
+	return statuses, err
+}
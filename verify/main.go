@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var debug = flag.Bool("d", false, "show debug output for network requests")
+
+var name = flag.String("name", "", "name of the check run (default: the command's name)")
+
+var number = flag.Int("n", 0, "report on the head commit of this pull request instead of HEAD")
+
+// maxOutput is the most text GitHub keeps in a check run's output.
+const maxOutput = 65535
+
+// locationRE matches compiler and linter messages such as
+// "main.go:12:5: undefined: foo".
+var locationRE = regexp.MustCompile(`^([^\s:]+):([0-9]+)(?::[0-9]+)?:\s*(.+)$`)
+
+var c *github.ApiClient
+
+var user, repo string
+
+func getHeadSha() (string, error) {
+	data, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", errors.New("'git rev-parse HEAD' failed")
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func getTopLevel() (string, error) {
+	data, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", errors.New("'git rev-parse --show-toplevel' failed")
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// annotate turns the file:line messages in output that refer to files in
+// the repository into annotations.
+func annotate(output, level string) []github.CheckRunAnnotation {
+	top, err := getTopLevel()
+	if err != nil {
+		return nil
+	}
+	var annotations []github.CheckRunAnnotation
+	for _, line := range strings.Split(output, "\n") {
+		matches := locationRE.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		abs, err := filepath.Abs(matches[1])
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(abs); err != nil || info.IsDir() {
+			continue
+		}
+		path, err := filepath.Rel(top, abs)
+		if err != nil || strings.HasPrefix(path, "..") {
+			continue
+		}
+		line, _ := strconv.Atoi(matches[2])
+		annotations = append(annotations, github.CheckRunAnnotation{
+			Path:            filepath.ToSlash(path),
+			StartLine:       line,
+			EndLine:         line,
+			AnnotationLevel: level,
+			Message:         matches[3],
+		})
+	}
+	return annotations
+}
+
+// runCommand runs args, copying its output to ours, and returns the
+// combined output and exit status.
+func runCommand(args []string) (string, int, error) {
+	var output bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = io.MultiWriter(os.Stdout, &output)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &output)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return output.String(), exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("could not run %s: %w", args[0], err)
+	}
+	return output.String(), 0, nil
+}
+
+// report creates a check run for the result of the command. Check runs
+// need GitHub App credentials, so if they are refused a commit status is
+// posted instead.
+func report(ctx context.Context, sha, checkName, command, output string, status int, started time.Time) error {
+	conclusion, title, level := "success", "Passed", github.AnnotationWarning
+	if status != 0 {
+		conclusion, title, level = "failure", fmt.Sprintf("Failed with exit status %d", status), github.AnnotationFailure
+	}
+	text := output
+	if len(text) > maxOutput-100 {
+		text = "...\n" + text[len(text)-(maxOutput-100):]
+	}
+	req := &github.CheckRunRequest{
+		Name:        checkName,
+		HeadSHA:     sha,
+		Conclusion:  conclusion,
+		StartedAt:   started.UTC().Format(time.RFC3339),
+		CompletedAt: time.Now().UTC().Format(time.RFC3339),
+		Output: &github.CheckRunOutput{
+			Title:       title,
+			Summary:     "`" + command + "`",
+			Text:        "```\n" + text + "\n```",
+			Annotations: annotate(output, level),
+		},
+	}
+	run, err := c.CreateCheckRun(ctx, user, repo, req)
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 403 {
+		fmt.Fprintf(os.Stderr, "Check runs require GitHub App credentials; reporting a commit status instead\n")
+		_, err = c.CreateStatus(ctx, user, repo, sha, &github.StatusRequest{
+			State:       conclusion,
+			Description: title,
+			Context:     checkName,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating status: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error creating check run: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Reported %s: %s\n", checkName, run.HtmlUrl)
+	return nil
+}
+
+func showError(err error) {
+	fmt.Printf("Error: %s\n", err)
+	var apiErr *github.APIError
+	if errors.As(err, &apiErr) && apiErr.DocumentationURL != "" {
+		fmt.Printf("See %s\n", apiErr.DocumentationURL)
+	}
+	os.Exit(1)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] [--] <command> [args...]\n\n", os.Args[0])
+		fmt.Fprint(os.Stderr, "Runs the command and reports whether it passed, with its output, as a check\n"+
+			"run on HEAD. Messages in the output of the form file:line: message become\n"+
+			"annotations. Exits with the command's exit status.\n\n")
+		fmt.Fprint(os.Stderr, "Options:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	args := flag.Args()
+	checkName := *name
+	if checkName == "" {
+		checkName = filepath.Base(args[0])
+	}
+
+	// Cancel in-flight requests on Ctrl-C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	remote, err := github.GetRemote()
+	if err != nil {
+		showError(err)
+	}
+	user, repo = remote.User, remote.Repo
+
+	c, err = github.ApiClientForHost(remote.Host)
+	if err != nil {
+		showError(err)
+	}
+	c.Debug = *debug
+
+	var sha string
+	if *number > 0 {
+		pull, err := c.GetPullRequest(ctx, user, repo, *number)
+		if err != nil {
+			showError(err)
+		}
+		sha = pull.Head.SHA
+	} else if sha, err = getHeadSha(); err != nil {
+		showError(err)
+	}
+
+	started := time.Now()
+	output, status, err := runCommand(args)
+	if err != nil {
+		showError(err)
+	}
+	if err := report(ctx, sha, checkName, strings.Join(args, " "), output, status, started); err != nil {
+		showError(err)
+	}
+	os.Exit(status)
+}
@@ -0,0 +1,194 @@
+package github
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a significant line of a YAML document.
+type yamlLine struct {
+	num     int
+	indent  int
+	content string
+}
+
+// parseYAML parses the block-style subset of YAML used by the hub and gh
+// configuration files: nested mappings, block sequences, and plain, single-
+// or double-quoted scalars. Mappings are returned as map[string]interface{},
+// sequences as []interface{} and scalars as string.
+func parseYAML(data []byte) (interface{}, error) {
+	var lines []*yamlLine
+	for i, text := range strings.Split(string(data), "\n") {
+		text = strings.TrimRight(stripYAMLComment(text), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, &yamlLine{num: i + 1, indent: len(text) - len(trimmed), content: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	v, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[next].num)
+	}
+	return v, nil
+}
+
+func parseYAMLBlock(lines []*yamlLine, i, indent int) (interface{}, int, error) {
+	if isYAMLSequenceItem(lines[i].content) {
+		return parseYAMLSequence(lines, i, indent)
+	}
+	return parseYAMLMapping(lines, i, indent)
+}
+
+func isYAMLSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+func parseYAMLSequence(lines []*yamlLine, i, indent int) (interface{}, int, error) {
+	var seq []interface{}
+	for i < len(lines) && lines[i].indent == indent && isYAMLSequenceItem(lines[i].content) {
+		rest := strings.TrimLeft(strings.TrimPrefix(lines[i].content, "-"), " ")
+		if rest == "" {
+			i++
+			if i < len(lines) && lines[i].indent > indent {
+				v, next, err := parseYAMLBlock(lines, i, lines[i].indent)
+				if err != nil {
+					return nil, 0, err
+				}
+				seq = append(seq, v)
+				i = next
+			} else {
+				seq = append(seq, nil)
+			}
+			continue
+		}
+		// Treat the item's content as the first line of a nested block
+		// indented to where the content starts.
+		itemIndent := indent + len(lines[i].content) - len(rest)
+		lines[i] = &yamlLine{num: lines[i].num, indent: itemIndent, content: rest}
+		if _, _, isKey := splitYAMLKey(rest); isKey || isYAMLSequenceItem(rest) {
+			v, next, err := parseYAMLBlock(lines, i, itemIndent)
+			if err != nil {
+				return nil, 0, err
+			}
+			seq = append(seq, v)
+			i = next
+		} else {
+			s, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, 0, fmt.Errorf("line %d: %s", lines[i].num, err)
+			}
+			seq = append(seq, s)
+			i++
+		}
+	}
+	return seq, i, nil
+}
+
+func parseYAMLMapping(lines []*yamlLine, i, indent int) (interface{}, int, error) {
+	m := make(map[string]interface{})
+	for i < len(lines) && lines[i].indent == indent {
+		line := lines[i]
+		key, value, ok := splitYAMLKey(line.content)
+		if !ok {
+			return nil, 0, fmt.Errorf("line %d: expected \"key: value\"", line.num)
+		}
+		k, err := parseYAMLScalar(key)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: %s", line.num, err)
+		}
+		i++
+		if value != "" {
+			if m[k], err = parseYAMLScalar(value); err != nil {
+				return nil, 0, fmt.Errorf("line %d: %s", line.num, err)
+			}
+			continue
+		}
+		// A key with no value introduces a nested block, which may be a
+		// sequence at the same indentation as the key.
+		if i < len(lines) && (lines[i].indent > indent ||
+			(lines[i].indent == indent && isYAMLSequenceItem(lines[i].content))) {
+			if m[k], i, err = parseYAMLBlock(lines, i, lines[i].indent); err != nil {
+				return nil, 0, err
+			}
+		} else {
+			m[k] = nil
+		}
+	}
+	if i < len(lines) && lines[i].indent > indent {
+		return nil, 0, fmt.Errorf("line %d: unexpected indentation", lines[i].num)
+	}
+	return m, i, nil
+}
+
+// splitYAMLKey splits "key: value" or "key:" at the first colon outside of
+// quotes that is followed by a space or the end of the line.
+func splitYAMLKey(content string) (key, value string, ok bool) {
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == ':' && (i == len(content)-1 || content[i+1] == ' '):
+			return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func parseYAMLScalar(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted string %s", s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("invalid single-quoted string %s", s)
+		}
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	}
+	return s, nil
+}
+
+// stripYAMLComment removes a trailing "# comment" that is not inside quotes.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == ':' || line[i-1] == '-' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,119 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+)
+
+type yamlMap = map[string]interface{}
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name, doc string
+		want      interface{}
+	}{
+		{
+			name: "gh hosts",
+			doc: `github.com:
+    user: alice
+    oauth_token: gho_one
+    git_protocol: https
+ghe.example.com:
+    user: bob
+    oauth_token: gho_two
+`,
+			want: yamlMap{
+				"github.com":      yamlMap{"user": "alice", "oauth_token": "gho_one", "git_protocol": "https"},
+				"ghe.example.com": yamlMap{"user": "bob", "oauth_token": "gho_two"},
+			},
+		},
+		{
+			name: "hub accounts",
+			doc: `---
+github.com:
+- user: alice
+  oauth_token: abc
+  protocol: https
+- user: bob
+  oauth_token: def
+`,
+			want: yamlMap{"github.com": []interface{}{
+				yamlMap{"user": "alice", "oauth_token": "abc", "protocol": "https"},
+				yamlMap{"user": "bob", "oauth_token": "def"},
+			}},
+		},
+		{
+			name: "indented sequence of scalars",
+			doc: `hosts:
+  - github.com
+  - "ghe.example.com"
+`,
+			want: yamlMap{"hosts": []interface{}{"github.com", "ghe.example.com"}},
+		},
+		{
+			name: "quoted values",
+			doc: `double: "a: b\tc"
+single: 'it''s'
+"quoted key": plain value
+url: https://example.com:8080/x
+`,
+			want: yamlMap{"double": "a: b\tc", "single": "it's", "quoted key": "plain value", "url": "https://example.com:8080/x"},
+		},
+		{
+			name: "comments",
+			doc: `# written by gh
+user: alice # trailing
+hash: "not # a comment"
+single: 'nor # this'
+fragment: a#b
+`,
+			want: yamlMap{"user": "alice", "hash": "not # a comment", "single": "nor # this", "fragment": "a#b"},
+		},
+		{
+			name: "nested users",
+			doc: `github.com:
+    users:
+        alice:
+            oauth_token: gho_alice
+        bob:
+    git_protocol: ssh
+    user: alice
+`,
+			want: yamlMap{"github.com": yamlMap{
+				"users":        yamlMap{"alice": yamlMap{"oauth_token": "gho_alice"}, "bob": nil},
+				"git_protocol": "ssh",
+				"user":         "alice",
+			}},
+		},
+		{
+			name: "empty",
+			doc:  "# nothing here\n\n",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("parseYAML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseYAML = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	for _, doc := range []string{
+		"a:\n\tb: c\n",
+		"a: b\n  c: d\n",
+		"just a scalar\n",
+		"a: \"unterminated\n",
+		"a: 'unterminated\n",
+	} {
+		if v, err := parseYAML([]byte(doc)); err == nil {
+			t.Errorf("parseYAML(%q) = %#v, want an error", doc, v)
+		}
+	}
+}